@@ -0,0 +1,141 @@
+package inji
+
+import (
+	"fmt"
+	"reflect"
+)
+
+//binding is an implementation bound to an interface,
+//either an object name or a concrete type
+type binding struct {
+	name string
+	t    reflect.Type
+}
+
+func (b binding) String() string {
+	if b.name != "" {
+		return b.name
+	}
+	return getTypeName(b.t)
+}
+
+//Implements bind the registered object to interfaces,
+//interface fields tagged `inject:""` are injected with it
+func Implements(ifaces ...reflect.Type) Option {
+	return func(o *options) {
+		o.implements = append(o.implements, ifaces...)
+	}
+}
+
+//Bind bind impl to the interface iface, impl is either the name of
+//an object or a reflect.Type of a struct ptr which will be auto created,
+//interface fields tagged `inject:""` and FindByType(iface) resolve to it
+func (g *Graph) Bind(iface reflect.Type, impl interface{}) error {
+	g.l.Lock()
+	defer g.l.Unlock()
+	if iface == nil || iface.Kind() != reflect.Interface {
+		return fmt.Errorf("bind target must be an interface,type=%v", iface)
+	}
+	switch i := impl.(type) {
+	case string:
+		if i == "" {
+			return fmt.Errorf("bind name can not be empty,interface=%v", iface)
+		}
+		g.bind(iface, binding{name: i})
+	case reflect.Type:
+		if !isStructPtr(i) || !i.Implements(iface) {
+			return fmt.Errorf("bind type must be a struct ptr implementing interface,type=%v,interface=%v", i, iface)
+		}
+		g.bind(iface, binding{t: i})
+	default:
+		return fmt.Errorf("bind impl must be a name or a reflect.Type,impl=%v,interface=%v", impl, iface)
+	}
+	return nil
+}
+
+func (g *Graph) bind(iface reflect.Type, b binding) {
+	if g.bindings == nil {
+		g.bindings = make(map[reflect.Type][]binding)
+	}
+	for _, bound := range g.bindings[iface] {
+		if bound == b {
+			return
+		}
+	}
+	g.bindings[iface] = append(g.bindings[iface], b)
+}
+
+func (g *Graph) unbind(iface reflect.Type, b binding) {
+	bs := g.bindings[iface]
+	for i, bound := range bs {
+		if bound == b {
+			g.bindings[iface] = append(bs[:i:i], bs[i+1:]...)
+			return
+		}
+	}
+}
+
+//checkImplements check the Implements option of o
+func (g *Graph) checkImplements(o *Object) error {
+	for _, iface := range o.implements {
+		if iface == nil || iface.Kind() != reflect.Interface || !o.reflectType.Implements(iface) {
+			return fmt.Errorf("%w,object does not implement interface,name=%s,type=%v,interface=%v", ErrInvalidRegistration, o.Name, o.reflectType, iface)
+		}
+	}
+	return nil
+}
+
+//bindImplements bind o to the interfaces of its Implements option
+func (g *Graph) bindImplements(o *Object) {
+	for _, iface := range o.implements {
+		g.bind(iface, binding{name: o.Name})
+	}
+}
+
+//bound return the bindings of iface, parent's come first
+func (g *Graph) bound(iface reflect.Type) []binding {
+	var ret []binding
+	if g.parent != nil {
+		g.readParent(func(p *Graph) {
+			ret = p.bound(iface)
+		})
+	}
+	return append(ret, g.bindings[iface]...)
+}
+
+//findBound find the only implementation bound to iface,
+//when create is true a bound struct ptr type is auto created if needed
+func (g *Graph) findBound(iface reflect.Type, create bool) (*Object, bool, error) {
+	bs := g.bound(iface)
+	if len(bs) <= 0 {
+		return nil, false, fmt.Errorf("no implementation bound to interface=%v", iface)
+	}
+	if len(bs) > 1 {
+		return nil, false, fmt.Errorf("too many implementations bound to interface=%v,bound=%v", iface, bs)
+	}
+
+	b := bs[0]
+	if b.name != "" {
+		found, ok := g.find(b.name)
+		if !ok {
+			return nil, false, fmt.Errorf("implementation name=%s bound to interface=%v not found", b.name, iface)
+		}
+		return found, false, nil
+	}
+	found, ok := g.findByType(b.t)
+	if ok {
+		return found, false, nil
+	}
+	if !create {
+		return nil, false, fmt.Errorf("implementation type=%v bound to interface=%v not created", b.t, iface)
+	}
+	_, err := g.register("", reflect.NewAt(b.t.Elem(), nil).Interface(), false, false, options{})
+	if err != nil {
+		return nil, false, err
+	}
+	found, ok = g.findByType(b.t)
+	if !ok {
+		return nil, false, fmt.Errorf("implementation type=%v bound to interface=%v not found", b.t, iface)
+	}
+	return found, true, nil
+}
@@ -0,0 +1,133 @@
+package inji
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var pluginType = reflect.TypeOf((*Plugin)(nil)).Elem()
+
+type PluginUser struct {
+	Plugin Plugin `inject:""`
+}
+
+func TestBind(t *testing.T) {
+	fmt.Println("############## test bind")
+	g := NewGraph()
+	defer g.Close()
+
+	_, err := g.Register("user", (*PluginUser)(nil))
+	if err == nil {
+		t.Error("nothing is bound to Plugin")
+		return
+	}
+	fmt.Println(err)
+
+	err = g.Bind(pluginType, reflect.TypeOf((*PluginA)(nil)))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if _, ok := g.FindByType(pluginType); ok {
+		t.Error("bound PluginA is not created yet")
+		return
+	}
+	user := g.RegisterOrFail("user", (*PluginUser)(nil)).(*PluginUser)
+	if _, ok := user.Plugin.(*PluginA); !ok {
+		t.Error("PluginA should be auto created", user.Plugin)
+		return
+	}
+	found, ok := g.FindByType(pluginType)
+	if !ok || found.Value != user.Plugin {
+		t.Error("PluginA should be found by interface", found)
+		return
+	}
+
+	err = g.Bind(pluginType, reflect.TypeOf((*Sin1)(nil)))
+	if err == nil {
+		t.Error("*Sin1 does not implement Plugin")
+		return
+	}
+
+	g.RegisterOrFail("conf", "##conf1")
+	_, err = g.RegisterWith("b", (*PluginB)(nil), Implements(pluginType))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, err = g.Register("user2", (*PluginUser)(nil))
+	if err == nil || !strings.Contains(err.Error(), "too many implementations") {
+		t.Error("Plugin should be ambiguous", err)
+		return
+	}
+	fmt.Println(err)
+	if _, ok := g.FindByType(pluginType); ok {
+		t.Error("ambiguous Plugin should not be found")
+		return
+	}
+
+	_, err = g.RegisterWith("sin1", &Sin1{}, Implements(pluginType))
+	if err == nil {
+		t.Error("*Sin1 does not implement Plugin")
+	}
+}
+
+func TestBindImplements(t *testing.T) {
+	fmt.Println("############## test bind implements")
+	g := NewGraph()
+	defer g.Close()
+
+	g.RegisterOrFail("conf", "##conf1")
+	_, err := g.RegisterWith("b", (*PluginB)(nil), Implements(pluginType))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	user := g.RegisterOrFail("user", (*PluginUser)(nil)).(*PluginUser)
+	if user.Plugin.Name() != "b:##conf1" {
+		t.Error("PluginB should be injected by interface", user.Plugin)
+		return
+	}
+
+	child := g.NewChild()
+	defer child.Close()
+	childUser := child.RegisterOrFail("user", (*PluginUser)(nil)).(*PluginUser)
+	if childUser.Plugin != user.Plugin {
+		t.Error("child should use the bindings of parent", childUser.Plugin)
+	}
+}
+
+func TestBindAfterClose(t *testing.T) {
+	fmt.Println("############## test bind after close")
+	g := NewGraph()
+	defer g.Close()
+
+	g.RegisterOrFail("conf", "##conf1")
+	_, err := g.RegisterWith("a", &PluginA{}, Implements(pluginType))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	err = g.Close()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	g.RegisterOrFail("conf", "##conf2")
+	_, err = g.RegisterWith("b", (*PluginB)(nil), Implements(pluginType))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	user, err := g.Register("user", (*PluginUser)(nil))
+	if err != nil {
+		t.Error("bindings of closed objects should be removed", err)
+		return
+	}
+	if name := user.(*PluginUser).Plugin.Name(); name != "b:##conf2" {
+		t.Error("PluginB should be injected by interface", name)
+	}
+}
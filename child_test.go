@@ -0,0 +1,66 @@
+package inji
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type Tenant struct {
+	Shared *Sin1  `inject:"shared"`
+	Own    *Sin1  `inject:"own"`
+	Conf   string `inject:"conf"`
+}
+
+func TestChild(t *testing.T) {
+	fmt.Println("############## test child")
+	root := NewGraph()
+	defer root.Close()
+
+	shared := root.RegisterOrFail("shared", &Sin1{Name: "shared"}).(*Sin1)
+	root.RegisterOrFail("conf", "root conf")
+
+	child := root.NewChild()
+	own := child.RegisterOrFail("own", &Sin1{Name: "own"}).(*Sin1)
+	//objects of parent can be hidden by the child
+	child.RegisterOrFail("conf", "child conf")
+	tenant := child.RegisterOrFail("tenant", (*Tenant)(nil)).(*Tenant)
+	if tenant.Shared != shared || tenant.Own != own || tenant.Conf != "child conf" {
+		t.Error("invalid tenant", tenant)
+		return
+	}
+
+	if _, ok := root.Find("own"); ok {
+		t.Error("own should not leak into root")
+		return
+	}
+	if _, ok := root.Find("tenant"); ok {
+		t.Error("tenant should not leak into root")
+		return
+	}
+	if o, ok := child.Find("shared"); !ok || o.Value != shared {
+		t.Error("child should find shared from root", o)
+		return
+	}
+	if o, ok := child.FindByType(reflect.TypeOf(tenant)); ok {
+		t.Error("tenant is not a singleton", o)
+		return
+	}
+	if root.Len() != 2 || child.Len() != 3 {
+		t.Error("invalid len", root.SPrint(), child.SPrint())
+		return
+	}
+
+	err := child.Close()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if o, ok := root.Find("shared"); !ok || o.closed {
+		t.Error("shared should be left running in root", o)
+		return
+	}
+	if o, ok := child.Find("shared"); !ok || o.Value != shared {
+		t.Error("child should still find shared from root", o)
+	}
+}
@@ -0,0 +1,319 @@
+package inji
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/facebookgo/structtag"
+	"gopkg.in/yaml.v3"
+)
+
+//ConfigSource load config values as a tree of maps,
+//keys of nested maps are joined by '.' when looked up, e.g. http.timeout
+type ConfigSource interface {
+	Load() (map[string]interface{}, error)
+}
+
+type ConfigSourceFunc func() (map[string]interface{}, error)
+
+func (f ConfigSourceFunc) Load() (map[string]interface{}, error) {
+	return f()
+}
+
+//MapSource is a config source of m, keys of m can be dotted
+func MapSource(m map[string]interface{}) ConfigSource {
+	return ConfigSourceFunc(func() (map[string]interface{}, error) {
+		return expandKeys(m), nil
+	})
+}
+
+//EnvSource load env vars starting with prefix,
+//the rest of the name is lower cased and '_' is turned into '.',
+//'__' is kept as '_', e.g. with prefix APP_
+//	APP_HTTP_TIMEOUT -> http.timeout
+//	APP_PATH__STRING -> path_string
+func EnvSource(prefix string) ConfigSource {
+	return ConfigSourceFunc(func() (map[string]interface{}, error) {
+		m := make(map[string]interface{})
+		for _, kv := range os.Environ() {
+			i := strings.Index(kv, "=")
+			if i < 0 || !strings.HasPrefix(kv[:i], prefix) {
+				continue
+			}
+			k := strings.ToLower(kv[len(prefix):i])
+			if k == "" {
+				continue
+			}
+			k = strings.Replace(k, "__", "\x00", -1)
+			k = strings.Replace(k, "_", ".", -1)
+			k = strings.Replace(k, "\x00", "_", -1)
+			m[k] = kv[i+1:]
+		}
+		return expandKeys(m), nil
+	})
+}
+
+//JSONFile load a json object from path
+func JSONFile(path string) ConfigSource {
+	return ConfigSourceFunc(func() (map[string]interface{}, error) {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		m := make(map[string]interface{})
+		err = json.Unmarshal(b, &m)
+		if err != nil {
+			return nil, fmt.Errorf("invalid json config,path=%s,err=%v", path, err)
+		}
+		return m, nil
+	})
+}
+
+//YAMLFile load a yaml mapping from path
+func YAMLFile(path string) ConfigSource {
+	return ConfigSourceFunc(func() (map[string]interface{}, error) {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		m := make(map[string]interface{})
+		err = yaml.Unmarshal(b, &m)
+		if err != nil {
+			return nil, fmt.Errorf("invalid yaml config,path=%s,err=%v", path, err)
+		}
+		return m, nil
+	})
+}
+
+//LoadConfig load sources on order, values of a later source
+//override the ones of former sources and former LoadConfig calls,
+//the loaded values are injected into fields tagged with
+//	`config:"http.timeout" default:"5s"`
+func (g *Graph) LoadConfig(sources ...ConfigSource) error {
+	g.l.Lock()
+	defer g.l.Unlock()
+
+	config := g.config
+	if config == nil {
+		config = make(map[string]interface{})
+	}
+	for i, s := range sources {
+		m, err := s.Load()
+		if err != nil {
+			return fmt.Errorf("load config fail,source=%d,err=%v", i, err)
+		}
+		mergeConfig(config, m)
+	}
+	g.config = config
+	return nil
+}
+
+//Config find the config value of the dotted key
+func (g *Graph) Config(key string) (interface{}, bool) {
+	g.l.RLock()
+	defer g.l.RUnlock()
+	return g.lookupConfig(key)
+}
+
+func (g *Graph) lookupConfig(key string) (interface{}, bool) {
+	var cur interface{} = g.config
+	for _, k := range strings.Split(key, ".") {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			cur = nil
+			break
+		}
+		cur, ok = m[k]
+		if !ok {
+			cur = nil
+			break
+		}
+	}
+	if cur == nil && g.parent != nil {
+		ok := false
+		g.readParent(func(p *Graph) {
+			cur, ok = p.lookupConfig(key)
+		})
+		return cur, ok
+	}
+	return cur, cur != nil
+}
+
+//expandKeys turn dotted keys of m into nested maps
+func expandKeys(m map[string]interface{}) map[string]interface{} {
+	ret := make(map[string]interface{})
+	for k, v := range m {
+		keys := strings.Split(k, ".")
+		cur := ret
+		for _, sub := range keys[:len(keys)-1] {
+			next, ok := cur[sub].(map[string]interface{})
+			if !ok {
+				next = make(map[string]interface{})
+				cur[sub] = next
+			}
+			cur = next
+		}
+		if sub, ok := v.(map[string]interface{}); ok {
+			v = expandKeys(sub)
+		}
+		last := keys[len(keys)-1]
+		if old, ok := cur[last].(map[string]interface{}); ok {
+			if sub, ok := v.(map[string]interface{}); ok {
+				mergeConfig(old, sub)
+				continue
+			}
+		}
+		cur[last] = v
+	}
+	return ret
+}
+
+//mergeConfig merge src into dst, maps are merged deeply
+func mergeConfig(dst map[string]interface{}, src map[string]interface{}) {
+	for k, v := range src {
+		sub, ok := v.(map[string]interface{})
+		if ok {
+			old, ok := dst[k].(map[string]interface{})
+			if ok {
+				mergeConfig(old, sub)
+				continue
+			}
+			copied := make(map[string]interface{})
+			mergeConfig(copied, sub)
+			v = copied
+		}
+		dst[k] = v
+	}
+}
+
+//injectConfig set field f of o with the config value of key
+func (g *Graph) injectConfig(o *Object, key string, f reflect.StructField, vf reflect.Value) error {
+	if !vf.CanSet() {
+		return fmt.Errorf("%w,config tag must on a public field!field=%s,type=%v", ErrInvalidRegistration, f.Name, o.reflectType)
+	}
+	var path []string
+	value, ok := g.lookupConfig(key)
+	if ok {
+		path = []string{key}
+	} else {
+		hasDefault, def, _ := structtag.Extract("default", string(f.Tag))
+		if !hasDefault {
+			_, canNilStr, _ := structtag.Extract("cannil", string(f.Tag))
+			if canNilStr == "true" {
+				return nil
+			}
+			return &MissingDependencyError{Object: o.Name, ObjectType: o.reflectType, Field: f.Name, Tag: key, Type: f.Type,
+				Err: fmt.Errorf("config key=%s not found", key), Path: g.resolvePath()}
+		}
+		value = def
+	}
+	if str, ok := value.(string); ok {
+		expanded, err := g.expandPath(str, path)
+		if err != nil {
+			return fmt.Errorf("config key=%s of field=%s not valid in object %s:%v,err=%w", key, f.Name, o.Name, o.reflectType, err)
+		}
+		value = expanded
+	}
+	v, err := g.convertConfig(value, f.Type)
+	if err != nil {
+		return fmt.Errorf("config key=%s of field=%s,type=%v not valid in object %s:%v,err=%w", key, f.Name, f.Type, o.Name, o.reflectType, err)
+	}
+	vf.Set(v)
+	return nil
+}
+
+//convertConfig convert a loaded config value into type t,
+//lists, maps and nested structs are converted item by item
+func (g *Graph) convertConfig(value interface{}, t reflect.Type) (reflect.Value, error) {
+	if value == nil {
+		return reflect.Zero(t), nil
+	}
+	vv := reflect.ValueOf(value)
+	if vv.Type().AssignableTo(t) {
+		return vv, nil
+	}
+	if g.canConvert(vv.Type(), t) {
+		return g.convert(vv, t)
+	}
+
+	ret := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.Slice:
+		var items []interface{}
+		switch v := value.(type) {
+		case []interface{}:
+			items = v
+		case string:
+			for _, s := range strings.Split(v, ",") {
+				items = append(items, strings.TrimSpace(s))
+			}
+		default:
+			return reflect.Value{}, fmt.Errorf("can not convert %v to %v", vv.Type(), t)
+		}
+		s := reflect.MakeSlice(t, 0, len(items))
+		for i, item := range items {
+			iv, err := g.convertConfig(item, t.Elem())
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("[%d]:%v", i, err)
+			}
+			s = reflect.Append(s, iv)
+		}
+		ret.Set(s)
+	case reflect.Map:
+		m, ok := value.(map[string]interface{})
+		if !ok || t.Key().Kind() != reflect.String {
+			return reflect.Value{}, fmt.Errorf("can not convert %v to %v", vv.Type(), t)
+		}
+		mv := reflect.MakeMapWithSize(t, len(m))
+		for k, item := range m {
+			iv, err := g.convertConfig(item, t.Elem())
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("[%s]:%v", k, err)
+			}
+			mv.SetMapIndex(reflect.ValueOf(k).Convert(t.Key()), iv)
+		}
+		ret.Set(mv)
+	case reflect.Struct:
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("can not convert %v to %v", vv.Type(), t)
+		}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" {
+				continue
+			}
+			_, key, _ := structtag.Extract("config", string(f.Tag))
+			if key == "" {
+				key = strings.ToLower(f.Name[:1]) + f.Name[1:]
+			}
+			item, ok := m[key]
+			if !ok {
+				_, def, _ := structtag.Extract("default", string(f.Tag))
+				if def == "" {
+					continue
+				}
+				item = def
+			}
+			iv, err := g.convertConfig(item, f.Type)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("%s:%v", key, err)
+			}
+			ret.Field(i).Set(iv)
+		}
+	case reflect.Ptr:
+		ev, err := g.convertConfig(value, t.Elem())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		p := reflect.New(t.Elem())
+		p.Elem().Set(ev)
+		ret.Set(p)
+	default:
+		return g.convert(vv, t)
+	}
+	return ret, nil
+}
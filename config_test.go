@@ -0,0 +1,124 @@
+package inji
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type HTTPConf struct {
+	Addr    string
+	Timeout time.Duration
+	Retry   int `config:"retries" default:"3"`
+}
+
+type ConfigUser struct {
+	Timeout time.Duration     `config:"http.timeout" default:"5s"`
+	Port    uint16            `config:"http.port"`
+	Debug   bool              `config:"debug" default:"false"`
+	Ratio   float64           `config:"ratio" default:"0.5"`
+	Hosts   []string          `config:"hosts"`
+	Ports   []int             `config:"ports" cannil:"true"`
+	Labels  map[string]string `config:"labels" cannil:"true"`
+	HTTP    *HTTPConf         `config:"http"`
+	Name    string            `config:"name" default:"app"`
+	Missing string            `config:"missing" cannil:"true"`
+	Dep     *Dep              `inject:""`
+}
+
+func writeFile(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	err := os.WriteFile(path, []byte(content), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestConfig(t *testing.T) {
+	fmt.Println("############## test config")
+	g := NewGraph()
+	defer g.Close()
+
+	jsonPath := writeFile(t, "app.json", `{"http":{"addr":"json","port":80,"timeout":"1s"},"hosts":["a","b"],"ratio":1}`)
+	yamlPath := writeFile(t, "app.yaml", "http:\n  port: 8080\n  retries: 5\nlabels:\n  env: test\nports: [1, 2]\n")
+	t.Setenv("INJI_TEST_DEBUG", "true")
+	t.Setenv("INJI_TEST_HTTP_TIMEOUT", "2s")
+	t.Setenv("INJI_TEST_PATH__NAME", "p")
+
+	err := g.LoadConfig(JSONFile(jsonPath), YAMLFile(yamlPath), EnvSource("INJI_TEST_"))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if v, ok := g.Config("path_name"); !ok || v != "p" {
+		t.Error("__ should be kept as _", v)
+		return
+	}
+
+	u := g.RegisterOrFail("user", (*ConfigUser)(nil)).(*ConfigUser)
+	if u.Timeout != 2*time.Second || u.Port != 8080 || !u.Debug || u.Ratio != 1 {
+		t.Error("later sources should override former ones", u)
+		return
+	}
+	if !reflect.DeepEqual(u.Hosts, []string{"a", "b"}) || !reflect.DeepEqual(u.Ports, []int{1, 2}) {
+		t.Error("invalid slices", u.Hosts, u.Ports)
+		return
+	}
+	if u.Labels["env"] != "test" || u.Name != "app" || u.Missing != "" || u.Dep == nil {
+		t.Error("invalid config user", u)
+		return
+	}
+	if u.HTTP == nil || u.HTTP.Addr != "json" || u.HTTP.Timeout != 2*time.Second || u.HTTP.Retry != 5 {
+		t.Error("invalid nested struct", u.HTTP)
+		return
+	}
+
+	child := g.NewChild()
+	defer child.Close()
+	err = child.LoadConfig(MapSource(map[string]interface{}{"http.port": "9090", "hosts": "c, d"}))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	cu := child.RegisterOrFail("user", (*ConfigUser)(nil)).(*ConfigUser)
+	if cu.Port != 9090 || cu.Timeout != 2*time.Second || !reflect.DeepEqual(cu.Hosts, []string{"c", "d"}) {
+		t.Error("child should override and fall back to parent config", cu)
+		return
+	}
+}
+
+func TestConfigInvalid(t *testing.T) {
+	fmt.Println("############## test config invalid")
+	g := NewGraph()
+	defer g.Close()
+
+	_, err := g.Register("user", (*ConfigUser)(nil))
+	if err == nil {
+		t.Error("config http.port is missing")
+		return
+	}
+	fmt.Println(err)
+
+	err = g.LoadConfig(MapSource(map[string]interface{}{"http.port": 70000, "hosts": []interface{}{"a"}}))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, err = g.Register("user", (*ConfigUser)(nil))
+	if err == nil {
+		t.Error("70000 overflows uint16")
+		return
+	}
+	fmt.Println(err)
+
+	err = g.LoadConfig(JSONFile(writeFile(t, "bad.json", "{")))
+	if err == nil {
+		t.Error("invalid json should fail")
+		return
+	}
+	fmt.Println(err)
+}
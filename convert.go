@@ -0,0 +1,296 @@
+package inji
+
+import (
+	"encoding"
+	"fmt"
+	"math"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//ConvertFunc convert v into the type it is registered to,
+//v is never nil
+type ConvertFunc func(v interface{}) (interface{}, error)
+
+//ConvertError is returned when a value can not be converted
+//into the type of the field it is injected to
+type ConvertError struct {
+	From  reflect.Type
+	To    reflect.Type
+	Value interface{}
+	Err   error
+}
+
+func (e *ConvertError) Error() string {
+	return fmt.Sprintf("convert %v to %v fail,value=%v,err=%v", e.From, e.To, e.Value, e.Err)
+}
+
+func (e *ConvertError) Unwrap() error {
+	return e.Err
+}
+
+type convertKey struct {
+	from reflect.Type
+	to   reflect.Type
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	urlType             = reflect.TypeOf(url.URL{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+//RegisterConverter register fn to convert values of type from into type to,
+//it is used when a dependency or a config value of type from is injected into
+//a field of type to, a converter of the graph overrides the ones of its parent
+//and the built-in conversions
+func (g *Graph) RegisterConverter(from reflect.Type, to reflect.Type, fn ConvertFunc) error {
+	g.l.Lock()
+	defer g.l.Unlock()
+	if from == nil || to == nil || fn == nil {
+		return fmt.Errorf("converter can not be nil,from=%v,to=%v", from, to)
+	}
+	if g.converters == nil {
+		g.converters = make(map[convertKey]ConvertFunc)
+	}
+	g.converters[convertKey{from: from, to: to}] = fn
+	return nil
+}
+
+//converter find the converter of from to to, the graph's own come first
+func (g *Graph) converter(from reflect.Type, to reflect.Type) (ConvertFunc, bool) {
+	fn, ok := g.converters[convertKey{from: from, to: to}]
+	if ok || g.parent == nil {
+		return fn, ok
+	}
+	g.readParent(func(p *Graph) {
+		fn, ok = p.converter(from, to)
+	})
+	return fn, ok
+}
+
+//convert convert v into type to by the registered converters,
+//then by the built-in ones
+func (g *Graph) convert(v reflect.Value, to reflect.Type) (reflect.Value, error) {
+	from := v.Type()
+	if fn, ok := g.converter(from, to); ok {
+		r, err := fn(v.Interface())
+		if err != nil {
+			return reflect.Value{}, &ConvertError{From: from, To: to, Value: v.Interface(), Err: err}
+		}
+		if r == nil {
+			return reflect.Zero(to), nil
+		}
+		rv := reflect.ValueOf(r)
+		if !rv.Type().AssignableTo(to) {
+			return reflect.Value{}, &ConvertError{From: from, To: to, Value: v.Interface(),
+				Err: fmt.Errorf("converter returned %v", rv.Type())}
+		}
+		return rv, nil
+	}
+	if from.AssignableTo(to) {
+		return v, nil
+	}
+	r, err := convertBuiltin(v, to)
+	if err != nil {
+		return reflect.Value{}, &ConvertError{From: from, To: to, Value: v.Interface(), Err: err}
+	}
+	return r, nil
+}
+
+//canConvert tell if from can be converted to to as a whole,
+//by a registered converter or a built-in conversion of a string
+func (g *Graph) canConvert(from reflect.Type, to reflect.Type) bool {
+	if _, ok := g.converter(from, to); ok {
+		return true
+	}
+	if from.Kind() != reflect.String {
+		return false
+	}
+	return isTextTarget(to) || to == durationType || to == urlType || to == reflect.PtrTo(urlType)
+}
+
+//isTextTarget tell if t or *t is a encoding.TextUnmarshaler
+func isTextTarget(t reflect.Type) bool {
+	if reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		return true
+	}
+	return t.Kind() == reflect.Ptr && t.Implements(textUnmarshalerType)
+}
+
+//convertBuiltin convert between scalar kinds,
+//strings are parsed into numbers, bools, durations, urls and TextUnmarshalers
+func convertBuiltin(v reflect.Value, to reflect.Type) (reflect.Value, error) {
+	if v.Kind() == reflect.String {
+		s := v.String()
+		switch {
+		case isTextTarget(to):
+			return unmarshalText(s, to)
+		case to == durationType:
+			d, err := time.ParseDuration(strings.TrimSpace(s))
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			return reflect.ValueOf(d), nil
+		case to == urlType || to == reflect.PtrTo(urlType):
+			u, err := url.Parse(strings.TrimSpace(s))
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			if to == urlType {
+				return reflect.ValueOf(*u), nil
+			}
+			return reflect.ValueOf(u), nil
+		}
+	}
+
+	ret := reflect.New(to).Elem()
+	switch to.Kind() {
+	case reflect.String:
+		s, err := convertString(v)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		ret.SetString(s)
+	case reflect.Bool:
+		b, err := convertBool(v)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		ret.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := convertInt(v)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if ret.OverflowInt(i) {
+			return reflect.Value{}, fmt.Errorf("%v overflows %v", i, to)
+		}
+		ret.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := convertUint(v)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if ret.OverflowUint(u) {
+			return reflect.Value{}, fmt.Errorf("%v overflows %v", u, to)
+		}
+		ret.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := convertFloat(v)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if ret.OverflowFloat(f) {
+			return reflect.Value{}, fmt.Errorf("%v overflows %v", f, to)
+		}
+		ret.SetFloat(f)
+	default:
+		return reflect.Value{}, fmt.Errorf("no converter registered")
+	}
+	return ret, nil
+}
+
+func unmarshalText(s string, to reflect.Type) (reflect.Value, error) {
+	if to.Kind() == reflect.Ptr && to.Implements(textUnmarshalerType) {
+		p := reflect.New(to.Elem())
+		err := p.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return p, nil
+	}
+	p := reflect.New(to)
+	err := p.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return p.Elem(), nil
+}
+
+func convertString(v reflect.Value) (string, error) {
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 32), nil
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64), nil
+	}
+	return "", fmt.Errorf("not a scalar")
+}
+
+func convertBool(v reflect.Value) (bool, error) {
+	switch v.Kind() {
+	case reflect.Bool:
+		return v.Bool(), nil
+	case reflect.String:
+		return strconv.ParseBool(strings.TrimSpace(v.String()))
+	}
+	return false, fmt.Errorf("not a bool")
+}
+
+func convertInt(v reflect.Value) (int64, error) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if v.Uint() > math.MaxInt64 {
+			return 0, fmt.Errorf("%v overflows int64", v.Uint())
+		}
+		return int64(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+			return 0, fmt.Errorf("%v is not an integer", f)
+		}
+		return int64(f), nil
+	case reflect.String:
+		return strconv.ParseInt(strings.TrimSpace(v.String()), 0, 64)
+	}
+	return 0, fmt.Errorf("not a number")
+}
+
+func convertUint(v reflect.Value) (uint64, error) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Int() < 0 {
+			return 0, fmt.Errorf("%v is negative", v.Int())
+		}
+		return uint64(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint(), nil
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if f != math.Trunc(f) || f < 0 || f >= math.MaxUint64 {
+			return 0, fmt.Errorf("%v is not an unsigned integer", f)
+		}
+		return uint64(f), nil
+	case reflect.String:
+		return strconv.ParseUint(strings.TrimSpace(v.String()), 0, 64)
+	}
+	return 0, fmt.Errorf("not a number")
+}
+
+func convertFloat(v reflect.Value) (float64, error) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	case reflect.String:
+		return strconv.ParseFloat(strings.TrimSpace(v.String()), 64)
+	}
+	return 0, fmt.Errorf("not a number")
+}
@@ -0,0 +1,173 @@
+package inji
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type Level int
+
+type Mode string
+
+type Upper string
+
+func (u *Upper) UnmarshalText(b []byte) error {
+	*u = Upper(strings.ToUpper(string(b)))
+	return nil
+}
+
+type Converted struct {
+	Int8     int8          `inject:"small"`
+	Uint     uint          `inject:"small"`
+	Float    float32       `inject:"small"`
+	Str      string        `inject:"small"`
+	Level    Level         `inject:"small"`
+	Timeout  time.Duration `inject:"timeout"`
+	Port     int           `inject:"port"`
+	Debug    bool          `inject:"debug"`
+	Mode     Mode          `inject:"mode"`
+	Upper    Upper         `inject:"mode"`
+	Addr     *url.URL      `inject:"addr"`
+	AddrVal  url.URL       `inject:"addr"`
+	IP       net.IP        `inject:"ip"`
+	Deadline time.Time     `inject:"deadline"`
+}
+
+func TestConvert(t *testing.T) {
+	fmt.Println("############## test convert")
+	g := NewGraph()
+	defer g.Close()
+
+	g.RegisterOrFail("small", 100)
+	g.RegisterOrFail("timeout", "3s")
+	g.RegisterOrFail("port", "8080")
+	g.RegisterOrFail("debug", "true")
+	g.RegisterOrFail("mode", "dev")
+	g.RegisterOrFail("addr", "http://localhost:80/path")
+	g.RegisterOrFail("ip", "127.0.0.1")
+	g.RegisterOrFail("deadline", "2020-01-02T03:04:05Z")
+
+	c := g.RegisterOrFail("converted", (*Converted)(nil)).(*Converted)
+	if c.Int8 != 100 || c.Uint != 100 || c.Float != 100 || c.Str != "100" || c.Level != 100 {
+		t.Error("invalid numeric conversion", c)
+		return
+	}
+	if c.Timeout != 3*time.Second || c.Port != 8080 || !c.Debug || c.Mode != "dev" || c.Upper != "DEV" {
+		t.Error("invalid string conversion", c)
+		return
+	}
+	if c.Addr == nil || c.Addr.Host != "localhost:80" || c.AddrVal.Path != "/path" {
+		t.Error("invalid url conversion", c.Addr, c.AddrVal)
+		return
+	}
+	if !c.IP.Equal(net.IPv4(127, 0, 0, 1)) || c.Deadline.Year() != 2020 {
+		t.Error("invalid text conversion", c.IP, c.Deadline)
+		return
+	}
+}
+
+func TestConvertInvalid(t *testing.T) {
+	fmt.Println("############## test convert invalid")
+	g := NewGraph()
+	defer g.Close()
+
+	type overflow struct {
+		V int8 `inject:"big"`
+	}
+	g.RegisterOrFail("big", 300)
+	_, err := g.Register("overflow", (*overflow)(nil))
+	if err == nil || !strings.Contains(err.Error(), "convert int to int8") {
+		t.Error("300 overflows int8", err)
+		return
+	}
+	fmt.Println(err)
+
+	type negative struct {
+		V uint `inject:"neg"`
+	}
+	g.RegisterOrFail("neg", -1)
+	_, err = g.Register("negative", (*negative)(nil))
+	if err == nil {
+		t.Error("-1 is not a uint")
+		return
+	}
+	fmt.Println(err)
+
+	type invalid struct {
+		V int `inject:"word"`
+	}
+	g.RegisterOrFail("word", "abc")
+	_, err = g.Register("invalid", (*invalid)(nil))
+	if err == nil || !strings.Contains(err.Error(), "convert string to int") {
+		t.Error("abc is not an int", err)
+		return
+	}
+	fmt.Println(err)
+}
+
+func TestRegisterConverter(t *testing.T) {
+	fmt.Println("############## test register converter")
+	g := NewGraph()
+	defer g.Close()
+
+	type csv struct {
+		V []string `inject:"list"`
+	}
+	g.RegisterOrFail("list", "a,b")
+	_, err := g.Register("csv", (*csv)(nil))
+	if err == nil {
+		t.Error("no converter of string to []string")
+		return
+	}
+
+	stringType := reflect.TypeOf("")
+	sliceType := reflect.TypeOf([]string{})
+	err = g.RegisterConverter(stringType, sliceType, func(v interface{}) (interface{}, error) {
+		return strings.Split(v.(string), ","), nil
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	c := g.RegisterOrFail("csv", (*csv)(nil)).(*csv)
+	if !reflect.DeepEqual(c.V, []string{"a", "b"}) {
+		t.Error("converter should be used", c.V)
+		return
+	}
+
+	child := g.NewChild()
+	defer child.Close()
+	if _, ok := child.converter(stringType, sliceType); !ok {
+		t.Error("child should use the converters of parent")
+		return
+	}
+
+	failure := errors.New("failure")
+	intType := reflect.TypeOf(0)
+	err = child.RegisterConverter(stringType, intType, func(v interface{}) (interface{}, error) {
+		return nil, failure
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	type port struct {
+		V int `inject:"port"`
+	}
+	child.RegisterOrFail("port", "80")
+	_, err = child.Register("port_user", (*port)(nil))
+	if err == nil {
+		t.Error("converter of child should override the built-in one")
+		return
+	}
+	var ce *ConvertError
+	if !errors.As(err, &ce) || !errors.Is(err, failure) || ce.To != intType {
+		t.Error("invalid convert error", err)
+	}
+}
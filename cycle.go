@@ -0,0 +1,206 @@
+package inji
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/facebookgo/structtag"
+	"github.com/teou/implmap"
+)
+
+//CycleStep is one object in a dependency cycle,
+//Field is the field of the object pointing to the next step,
+//it is empty on the last step
+type CycleStep struct {
+	Name  string
+	Type  reflect.Type
+	Field string
+}
+
+func (s CycleStep) String() string {
+	if s.Field == "" {
+		return fmt.Sprintf("%s(%v)", s.Name, s.Type)
+	}
+	return fmt.Sprintf("%s(%v).%s", s.Name, s.Type, s.Field)
+}
+
+//CycleError is returned when objects depend on each other,
+//the first and the last step of Path are the same object
+type CycleError struct {
+	Path []CycleStep
+}
+
+func (e *CycleError) Error() string {
+	buf := bytes.NewBufferString("dependency cycle found,path=")
+	for i, s := range e.Path {
+		if i > 0 {
+			buf.WriteString(" -> ")
+		}
+		buf.WriteString(s.String())
+	}
+	return buf.String()
+}
+
+//resolveFrame is an object being injected,
+//field is the field being resolved now
+type resolveFrame struct {
+	name  string
+	t     reflect.Type
+	field string
+	//time spent on objects created while resolving this one
+	nested time.Duration
+}
+
+//enter push name to the resolving stack,
+//if name is already being resolved a *CycleError is returned
+func (g *Graph) enter(name string, t reflect.Type) (*resolveFrame, error) {
+	for i, f := range g.resolving {
+		if f.name != name {
+			continue
+		}
+		var path []CycleStep
+		for _, p := range g.resolving[i:] {
+			path = append(path, CycleStep{Name: p.name, Type: p.t, Field: p.field})
+		}
+		path = append(path, CycleStep{Name: name, Type: t})
+		return nil, &CycleError{Path: path}
+	}
+	f := &resolveFrame{name: name, t: t}
+	g.resolving = append(g.resolving, f)
+	return f, nil
+}
+
+func (g *Graph) leave() {
+	g.resolving = g.resolving[:len(g.resolving)-1]
+}
+
+//Registration is a pending g.Register call
+type Registration struct {
+	Name  string
+	Value interface{}
+}
+
+//Validate check regs and the objects they will auto create
+//for dependency cycles, nothing is created or started,
+//objects already in graph end the check of a path
+func (g *Graph) Validate(regs ...Registration) error {
+	g.l.RLock()
+	defer g.l.RUnlock()
+
+	pending := make(map[string]reflect.Type)
+	var names []string
+	for _, r := range regs {
+		t := reflect.TypeOf(r.Value)
+		if t == nil {
+			return fmt.Errorf("%w,register nil on name=%s", ErrInvalidRegistration, r.Name)
+		}
+		name := r.Name
+		if name == "" && isStructPtr(t) {
+			name = getTypeName(t)
+		}
+		if name == "" {
+			return fmt.Errorf("%w,name can not be empty,name=%s,type=%v", ErrInvalidRegistration, name, t)
+		}
+		pending[name] = t
+		names = append(names, name)
+	}
+
+	v := &validator{g: g, pending: pending, done: make(map[string]bool)}
+	for _, name := range names {
+		err := v.walk(name, pending[name])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type validator struct {
+	g       *Graph
+	pending map[string]reflect.Type
+	done    map[string]bool
+	stack   []CycleStep
+}
+
+func (v *validator) walk(name string, t reflect.Type) error {
+	if v.done[name] {
+		return nil
+	}
+	for i, s := range v.stack {
+		if s.Name == name {
+			path := append([]CycleStep{}, v.stack[i:]...)
+			path = append(path, CycleStep{Name: name, Type: t})
+			return &CycleError{Path: path}
+		}
+	}
+
+	st := t
+	if st.Kind() == reflect.Ptr {
+		st = st.Elem()
+	}
+	if st.Kind() != reflect.Struct {
+		v.done[name] = true
+		return nil
+	}
+
+	v.stack = append(v.stack, CycleStep{Name: name, Type: t})
+	for i := 0; i < st.NumField(); i++ {
+		f := st.Field(i)
+		ok, tag, err := structtag.Extract("inject", string(f.Tag))
+		if err != nil {
+			return fmt.Errorf("%w,extract tag fail,f=%s,err=%v", ErrInvalidRegistration, f.Name, err)
+		}
+		if !ok {
+			continue
+		}
+		depName, depType := v.dependency(tag, f)
+		if depType == nil {
+			continue
+		}
+		v.stack[len(v.stack)-1].Field = f.Name
+		err = v.walk(depName, depType)
+		if err != nil {
+			return err
+		}
+	}
+	v.stack = v.stack[:len(v.stack)-1]
+	v.done[name] = true
+	return nil
+}
+
+//dependency find what field f will be injected with,
+//a nil type means nothing needs to be checked
+func (v *validator) dependency(tag string, f reflect.StructField) (string, reflect.Type) {
+	name := tag
+	if name == "" {
+		name = getTypeName(f.Type)
+	}
+	if t, ok := v.pending[name]; ok {
+		return name, t
+	}
+	if found, ok := v.g.find(name); ok {
+		if found.template {
+			return name, found.reflectType
+		}
+		return "", nil
+	}
+	if tag == "" && f.Type.Kind() == reflect.Interface {
+		bs := v.g.bound(f.Type)
+		if len(bs) == 1 && bs[0].t != nil {
+			return getTypeName(bs[0].t), bs[0].t
+		}
+		return "", nil
+	}
+	_, scopeStr, _ := structtag.Extract("scope", string(f.Tag))
+	if isStructPtr(f.Type) || scopeStr == ScopePrototype {
+		return name, f.Type
+	}
+	for _, impl := range implmap.Get(tag) {
+		if impl != nil && impl.AssignableTo(f.Type) {
+			return name, impl
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,178 @@
+package inji
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+//decorator wraps the objects of name, or of type t
+type decorator struct {
+	name  string
+	t     reflect.Type
+	fn    reflect.Value
+	label string
+}
+
+func (d decorator) matches(o *Object) bool {
+	if o.template || o.prototype {
+		return false
+	}
+	if d.name != "" {
+		return d.name == o.Name
+	}
+	if d.t.Kind() == reflect.Interface {
+		return o.reflectType.Implements(d.t)
+	}
+	return o.reflectType == d.t
+}
+
+//Decorate wrap the object of target with fn, target is either a name
+//or a reflect.Type, fn is like func(orig T) T or func(orig T) (T, error),
+//consumers get the decorated value, the ones already injected are
+//reinjected like Replace does, decorators of one object are applied
+//on the order of Decorate calls, Start and Close are still called
+//on the original object, e.g.
+//	g.Decorate(reflect.TypeOf((*Client)(nil)), func(c *Client) *Client {...})
+func (g *Graph) Decorate(target interface{}, fn interface{}) error {
+	g.l.Lock()
+	defer g.l.Unlock()
+
+	fv := reflect.ValueOf(fn)
+	if fv.Kind() != reflect.Func {
+		return fmt.Errorf("decorator must be a func,fn=%v", fn)
+	}
+	ft := fv.Type()
+	if ft.NumIn() != 1 || ft.NumOut() < 1 || ft.NumOut() > 2 ||
+		ft.Out(0) != ft.In(0) || (ft.NumOut() == 2 && ft.Out(1) != errorType) {
+		return fmt.Errorf("decorator must be like func(T) T or func(T) (T, error),type=%v", ft)
+	}
+	d := decorator{fn: fv, label: funcName(fv)}
+	switch t := target.(type) {
+	case string:
+		if t == "" {
+			return fmt.Errorf("decorate name can not be empty,decorator=%s", d.label)
+		}
+		d.name = t
+	case reflect.Type:
+		if t == nil || !t.AssignableTo(ft.In(0)) {
+			return fmt.Errorf("decorate type=%v is not a %v,decorator=%s", t, ft.In(0), d.label)
+		}
+		d.t = t
+	default:
+		return fmt.Errorf("decorate target must be a name or a reflect.Type,target=%v", target)
+	}
+
+	iter := g.named.IterFunc()
+	seen := make(map[*Object]bool)
+	var decorated []*Object
+	for kv, ok := iter(); ok; kv, ok = iter() {
+		o, ok := kv.Value.(*Object)
+		if !ok || seen[o] || !d.matches(o) {
+			continue
+		}
+		seen[o] = true
+		err := g.applyDecorator(o, d)
+		if err != nil {
+			return err
+		}
+		decorated = append(decorated, o)
+	}
+	g.decorators = append(g.decorators, d)
+
+	var errs []error
+	for _, o := range decorated {
+		for _, c := range g.consumers(o) {
+			err := g.reinject(c, o, o)
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func funcName(fv reflect.Value) string {
+	name := runtime.FuncForPC(fv.Pointer()).Name()
+	return name[strings.LastIndex(name, "/")+1:]
+}
+
+//decorate apply the decorators of parent then of g to o
+func (g *Graph) decorate(o *Object) error {
+	for _, d := range g.allDecorators() {
+		if !d.matches(o) {
+			continue
+		}
+		err := g.applyDecorator(o, d)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *Graph) allDecorators() []decorator {
+	var ret []decorator
+	if g.parent != nil {
+		g.readParent(func(p *Graph) {
+			ret = p.allDecorators()
+		})
+	}
+	return append(ret, g.decorators...)
+}
+
+func (g *Graph) applyDecorator(o *Object, d decorator) error {
+	v, err := d.call(o.Name, o.value())
+	if err != nil {
+		return err
+	}
+	o.decorated = v
+	o.decorators = append(o.decorators, d.label)
+	return nil
+}
+
+//redecorate apply the decorators of o to v, a new value of o,
+//o is not changed
+func (g *Graph) redecorate(o *Object, v interface{}) (interface{}, []string, error) {
+	var labels []string
+	for _, d := range g.allDecorators() {
+		if !d.matches(o) {
+			continue
+		}
+		var err error
+		v, err = d.call(o.Name, v)
+		if err != nil {
+			return nil, nil, err
+		}
+		labels = append(labels, d.label)
+	}
+	return v, labels, nil
+}
+
+//call decorate cur, the value of the object of name
+func (d decorator) call(name string, cur interface{}) (interface{}, error) {
+	in := d.fn.Type().In(0)
+	if !reflect.TypeOf(cur).AssignableTo(in) {
+		return nil, fmt.Errorf("decorator=%s can not decorate %s:%v,want=%v", d.label, name, reflect.TypeOf(cur), in)
+	}
+	out := d.fn.Call([]reflect.Value{reflect.ValueOf(cur)})
+	if len(out) == 2 && !out[1].IsNil() {
+		return nil, fmt.Errorf("decorate object fail,name=%s,decorator=%s,err=%w", name, d.label, out[1].Interface().(error))
+	}
+	v := out[0].Interface()
+	if v == nil || (canNil(v) && isNil(v)) {
+		return nil, fmt.Errorf("decorator returned nil,name=%s,decorator=%s", name, d.label)
+	}
+	return v, nil
+}
+
+//value return the value injected into consumers,
+//the decorated one if o is decorated
+func (o *Object) value() interface{} {
+	if len(o.decorators) > 0 {
+		return o.decorated
+	}
+	return o.Value
+}
@@ -0,0 +1,196 @@
+package inji
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type Service interface {
+	Call() string
+}
+
+type RealService struct {
+	started bool
+	closed  bool
+}
+
+func (s *RealService) Call() string {
+	return "real"
+}
+
+func (s *RealService) Start() error {
+	s.started = true
+	return nil
+}
+
+func (s *RealService) Close() {
+	s.closed = true
+}
+
+type wrappedService struct {
+	layer string
+	next  Service
+}
+
+func (w *wrappedService) Call() string {
+	return w.layer + "(" + w.next.Call() + ")"
+}
+
+func metrics(s Service) Service {
+	return &wrappedService{layer: "metrics", next: s}
+}
+
+func retry(s Service) (Service, error) {
+	return &wrappedService{layer: "retry", next: s}, nil
+}
+
+type ServiceUser struct {
+	Service Service `inject:"service"`
+}
+
+type ServiceHost struct {
+	Service *RealService `inject:"plain"`
+}
+
+func TestDecorate(t *testing.T) {
+	fmt.Println("############## test decorate")
+	g := NewGraph()
+
+	serviceType := reflect.TypeOf((*Service)(nil)).Elem()
+	err := g.Decorate(serviceType, metrics)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	real := g.RegisterOrFail("service", &RealService{}).(*RealService)
+	err = g.Decorate("service", retry)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	u := g.RegisterOrFail("user", (*ServiceUser)(nil)).(*ServiceUser)
+	if u.Service.Call() != "retry(metrics(real))" {
+		t.Error("decorators should compose on declared order", u.Service.Call())
+		return
+	}
+	if !real.started {
+		t.Error("original object should be started")
+		return
+	}
+	s, err := GetNamed[Service](g, "service")
+	if err != nil || s != u.Service {
+		t.Error("get should return the decorated value", err)
+		return
+	}
+
+	tree := g.SPrintTree()
+	if !strings.Contains(tree, "[decorators=inji.metrics -> inji.retry]") {
+		t.Error("tree should show decorators", tree)
+		return
+	}
+	fmt.Println(tree)
+	j, _ := g.JSON()
+	if !strings.Contains(string(j), `"inji.metrics"`) {
+		t.Error("json should show decorators", string(j))
+		return
+	}
+
+	g.RegisterOrFail("plain", &RealService{})
+	_, err = g.Register("host", (*ServiceHost)(nil))
+	if err == nil {
+		t.Error("decorated Service is not a *RealService")
+		return
+	}
+	fmt.Println(err)
+
+	g.Close()
+	if !real.closed {
+		t.Error("original object should be closed")
+	}
+}
+
+func TestDecorateInvalid(t *testing.T) {
+	fmt.Println("############## test decorate invalid")
+	g := NewGraph()
+	defer g.Close()
+
+	err := g.Decorate("service", func(s Service) string { return "" })
+	if err == nil {
+		t.Error("decorator should return its param type")
+		return
+	}
+	err = g.Decorate(reflect.TypeOf(""), metrics)
+	if err == nil {
+		t.Error("string is not a Service")
+		return
+	}
+	fail := errors.New("fail")
+	err = g.Decorate("service", func(s Service) (Service, error) { return nil, fail })
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, err = g.Register("service", &RealService{})
+	if !errors.Is(err, fail) {
+		t.Error("decorator error should fail the registration", err)
+		return
+	}
+	if _, ok := g.Find("service"); ok {
+		t.Error("failed registration should be rolled back")
+	}
+}
+
+type DynamicUser struct {
+	N *Value[int] `inject:"n"`
+}
+
+func TestDecorateExisting(t *testing.T) {
+	fmt.Println("############## test decorate existing")
+	g := NewTestGraph(t)
+	g.RegisterOrFail("service", &RealService{})
+	g.RegisterOrFail("n", 1)
+	u := g.RegisterOrFail("user", (*ServiceUser)(nil)).(*ServiceUser)
+	du := g.RegisterOrFail("dynamic_user", (*DynamicUser)(nil)).(*DynamicUser)
+
+	err := g.Decorate("service", metrics)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if u.Service.Call() != "metrics(real)" {
+		t.Error("existing consumers should be reinjected", u.Service.Call())
+		return
+	}
+
+	err = g.Decorate("n", func(n int) int { return n * 10 })
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if du.N.Get() != 10 {
+		t.Error("existing dynamic consumers should be reinjected", du.N.Get())
+		return
+	}
+	err = g.Update("n", 2)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if du.N.Get() != 20 {
+		t.Error("updated value should be decorated", du.N.Get())
+		return
+	}
+	n, err := GetNamed[int](g, "n")
+	if err != nil || n != 20 {
+		t.Error("consumers after update should get the decorated value", n, err)
+		return
+	}
+	o, _ := g.Find("n")
+	if len(o.watchers) != 1 {
+		t.Error("reinject should not watch twice", o.watchers)
+	}
+}
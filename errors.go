@@ -0,0 +1,258 @@
+package inji
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+//ErrNotFound is wrapped by the errors of Get and GetNamed
+//when there is no such object in graph, *MissingDependencyError is ErrNotFound too
+var ErrNotFound = errors.New("object not found")
+
+//ErrAlreadyRegistered is wrapped when a name is registered twice
+var ErrAlreadyRegistered = errors.New("already registered")
+
+//ErrInvalidRegistration is wrapped when a registration is rejected before
+//anything is resolved, e.g. an empty name, a nil value, an inject tag on a
+//private field or a provider not like func(deps...) (T, error),
+//errors of placeholders, config sources and Start are returned as they are,
+//or wrapped by *StartError and *ConvertError
+var ErrInvalidRegistration = errors.New("invalid registration")
+
+//MissingDependencyError is returned when nothing can be injected
+//into Field of Object, Tag is the name(or group, or config key) looked up,
+//Err is the reason if any,
+//Path is the injection path from the registered object down to Object,
+//Suggestions are names of objects with a similar name or a compatible type
+type MissingDependencyError struct {
+	Object      string
+	ObjectType  reflect.Type
+	Field       string
+	Tag         string
+	Type        reflect.Type
+	Err         error
+	Path        []CycleStep
+	Suggestions []string
+}
+
+func (e *MissingDependencyError) Error() string {
+	s := fmt.Sprintf("dependency field=%s,tag=%s,type=%v not found in object %s:%v", e.Field, e.Tag, e.Type, e.Object, e.ObjectType)
+	if len(e.Path) > 0 {
+		s += ",path=" + sprintPath(e.Path, e.Tag, e.Type)
+	}
+	if len(e.Suggestions) > 0 {
+		s += ",suggestions=" + strings.Join(e.Suggestions, ",")
+	}
+	if e.Err != nil {
+		s += ",err=" + e.Err.Error()
+	}
+	return s
+}
+
+func (e *MissingDependencyError) Unwrap() error {
+	return e.Err
+}
+
+func (e *MissingDependencyError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+//TypeMismatchError is returned when an object is not of the wanted type,
+//Object, Field and Path are set when the object is injected into a field
+type TypeMismatchError struct {
+	Name       string
+	Want       reflect.Type
+	Got        reflect.Type
+	Object     string
+	ObjectType reflect.Type
+	Field      string
+	Err        error
+	Path       []CycleStep
+}
+
+func (e *TypeMismatchError) Error() string {
+	s := ""
+	if e.Field == "" {
+		s = fmt.Sprintf("type mismatch,name=%s,want=%v,got=%v", e.Name, e.Want, e.Got)
+	} else {
+		s = fmt.Sprintf("dependency name=%s,type=%v not valid for field=%s,type=%v in object %s:%v", e.Name, e.Got, e.Field, e.Want, e.Object, e.ObjectType)
+	}
+	if len(e.Path) > 0 {
+		s += ",path=" + sprintPath(e.Path, e.Name, e.Got)
+	}
+	if e.Err != nil {
+		s += ",err=" + e.Err.Error()
+	}
+	return s
+}
+
+func (e *TypeMismatchError) Unwrap() error {
+	return e.Err
+}
+
+//StartError wraps the error returned by Start of an object
+type StartError struct {
+	Name string
+	Type reflect.Type
+	Err  error
+}
+
+func (e *StartError) Error() string {
+	return fmt.Sprintf("Start object fail,name=%v,err=%v", e.Name, e.Err)
+}
+
+func (e *StartError) Unwrap() error {
+	return e.Err
+}
+
+//sprintPath print path like
+//	dep.Test -> test.Target -> "target" (int)
+func sprintPath(path []CycleStep, name string, t reflect.Type) string {
+	buf := &bytes.Buffer{}
+	for _, p := range path {
+		fmt.Fprintf(buf, "%s.%s -> ", p.Name, p.Field)
+	}
+	if name != "" {
+		fmt.Fprintf(buf, "%q ", name)
+	}
+	fmt.Fprintf(buf, "(%v)", t)
+	return buf.String()
+}
+
+//resolvePath return the objects being resolved now and their fields being injected
+func (g *Graph) resolvePath() []CycleStep {
+	path := make([]CycleStep, 0, len(g.resolving))
+	for _, f := range g.resolving {
+		path = append(path, CycleStep{Name: f.name, Type: f.t, Field: f.field})
+	}
+	return path
+}
+
+//missing return a *MissingDependencyError of field f of o
+func (g *Graph) missing(o *Object, f reflect.StructField, tag string, err error) error {
+	return &MissingDependencyError{Object: o.Name, ObjectType: o.reflectType, Field: f.Name, Tag: tag, Type: f.Type, Err: err,
+		Path: g.resolvePath(), Suggestions: g.suggest(tag, f.Type)}
+}
+
+//mismatch return a *TypeMismatchError of found injected into field f of o
+func (g *Graph) mismatch(o *Object, f reflect.StructField, found *Object, err error) error {
+	return &TypeMismatchError{Name: found.Name, Want: f.Type, Got: found.reflectType,
+		Object: o.Name, ObjectType: o.reflectType, Field: f.Name, Err: err, Path: g.resolvePath()}
+}
+
+//maxSuggestions is the max number of suggestions of a *MissingDependencyError
+const maxSuggestions = 5
+
+//suggest find objects of graph and its parents that can be meant by name or t,
+//objects of a compatible type come first, then the ones of similar names
+func (g *Graph) suggest(name string, t reflect.Type) []string {
+	type candidate struct {
+		name     string
+		typed    bool
+		distance int
+		order    int
+	}
+	var candidates []candidate
+	seen := make(map[string]bool)
+	g.eachObject(func(o *Object) {
+		if o.Name == "" || seen[o.Name] {
+			return
+		}
+		c := candidate{name: o.Name, distance: -1, order: len(seen)}
+		c.typed = t.Kind() != reflect.Slice && t.Kind() != reflect.Map && o.reflectType.AssignableTo(t)
+		if name != "" {
+			if d, ok := similar(name, o.Name); ok {
+				c.distance = d
+			}
+		}
+		if !c.typed && c.distance < 0 {
+			return
+		}
+		seen[o.Name] = true
+		candidates = append(candidates, c)
+	})
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.typed != b.typed {
+			return a.typed
+		}
+		if a.distance != b.distance {
+			return b.distance < 0 || (a.distance >= 0 && a.distance < b.distance)
+		}
+		return a.order < b.order
+	})
+	var ret []string
+	for _, c := range candidates {
+		if len(ret) == maxSuggestions {
+			break
+		}
+		ret = append(ret, c.name)
+	}
+	return ret
+}
+
+//eachObject call fn with every object of graph, then the ones of its parents
+func (g *Graph) eachObject(fn func(o *Object)) {
+	iter := g.named.IterFunc()
+	for kv, ok := iter(); ok; kv, ok = iter() {
+		o, ok := kv.Value.(*Object)
+		if ok {
+			fn(o)
+		}
+	}
+	if g.parent != nil {
+		g.readParent(func(p *Graph) {
+			p.eachObject(fn)
+		})
+	}
+}
+
+//similar tell if a and b are similar names ignoring case,
+//by a small edit distance or one containing the other
+func similar(a, b string) (int, bool) {
+	a, b = strings.ToLower(a), strings.ToLower(b)
+	d := distance(a, b)
+	limit := len(a) / 3
+	if limit < 1 {
+		limit = 1
+	}
+	if d <= limit {
+		return d, true
+	}
+	if len(a) > 2 && len(b) > 2 && (strings.Contains(a, b) || strings.Contains(b, a)) {
+		return d, true
+	}
+	return 0, false
+}
+
+//distance is the levenshtein distance of a and b
+func distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = prev[j-1] + cost
+			if prev[j]+1 < cur[j] {
+				cur[j] = prev[j] + 1
+			}
+			if cur[j-1]+1 < cur[j] {
+				cur[j] = cur[j-1] + 1
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,223 @@
+package inji
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+var errBadStart = errors.New("bad start")
+
+type BadStarter struct {
+}
+
+func (b *BadStarter) Start() error {
+	return errBadStart
+}
+
+type MissingUser struct {
+	Name string `inject:"nope"`
+}
+
+type MismatchUser struct {
+	Dep *BadStarter `inject:"dep"`
+}
+
+func TestTypedErrors(t *testing.T) {
+	fmt.Println("############## test typed errors")
+	g := NewTestGraph(t)
+
+	g.RegisterOrFail("dep", &Dep{})
+	_, err := g.Register("dep", &Dep{})
+	if !errors.Is(err, ErrAlreadyRegistered) {
+		t.Error("should be already registered", err)
+		return
+	}
+
+	_, err = g.Register("missing", (*MissingUser)(nil))
+	var me *MissingDependencyError
+	if !errors.As(err, &me) || !errors.Is(err, ErrNotFound) {
+		t.Error("should be missing dependency", err)
+		return
+	}
+	fmt.Println(err)
+	if me.Object != "missing" || me.Field != "Name" || me.Tag != "nope" || me.Type.Kind().String() != "string" {
+		t.Error("invalid missing dependency error", me)
+		return
+	}
+
+	_, err = g.Register("mismatch", (*MismatchUser)(nil))
+	var te *TypeMismatchError
+	if !errors.As(err, &te) {
+		t.Error("should be type mismatch", err)
+		return
+	}
+	fmt.Println(err)
+	if te.Name != "dep" || te.Object != "mismatch" || te.Field != "Dep" || te.Got != typeOf[*Dep]() || te.Want != typeOf[*BadStarter]() {
+		t.Error("invalid type mismatch error", te)
+		return
+	}
+	var ce *ConvertError
+	if !errors.As(err, &ce) {
+		t.Error("convert error should be wrapped", err)
+		return
+	}
+
+	_, err = g.Register("bad", &BadStarter{})
+	var se *StartError
+	if !errors.As(err, &se) || !errors.Is(err, errBadStart) {
+		t.Error("should be start error", err)
+		return
+	}
+	if se.Name != "bad" || se.Type != typeOf[*BadStarter]() {
+		t.Error("invalid start error", se)
+		return
+	}
+	if _, ok := g.Find("bad"); ok {
+		t.Error("bad should not be registered")
+	}
+}
+
+func TestTypedPanic(t *testing.T) {
+	fmt.Println("############## test typed panic")
+	g := NewTestGraph(t)
+	g.RegisterOrFail("dep", &Dep{})
+
+	var recovered interface{}
+	func() {
+		defer func() {
+			recovered = recover()
+		}()
+		g.RegisterOrFail("dep", &Dep{})
+	}()
+	err, ok := recovered.(error)
+	if !ok || !errors.Is(err, ErrAlreadyRegistered) {
+		t.Error("should panic with already registered", recovered)
+		return
+	}
+
+	func() {
+		defer func() {
+			recovered = recover()
+		}()
+		g.RegisterOrFail("missing", (*MissingUser)(nil))
+	}()
+	var me *MissingDependencyError
+	err, ok = recovered.(error)
+	if !ok || !errors.As(err, &me) {
+		t.Error("should panic with missing dependency", recovered)
+	}
+}
+
+type PathTarget struct {
+	Target int `inject:"target"`
+}
+
+type PathTest struct {
+	Target *PathTarget `inject:"pathTarget"`
+}
+
+type PathDep struct {
+	Test *PathTest `inject:"test"`
+}
+
+func TestMissingPath(t *testing.T) {
+	fmt.Println("############## test missing path")
+	g := NewTestGraph(t)
+	g.RegisterOrFail("targets", "a")
+	g.RegisterOrFail("port", 8080)
+	g.RegisterOrFail("name", "b")
+
+	_, err := g.Register("dep", (*PathDep)(nil))
+	var me *MissingDependencyError
+	if !errors.As(err, &me) {
+		t.Error("should be missing dependency", err)
+		return
+	}
+	fmt.Println(err)
+	if !strings.Contains(err.Error(), `path=dep.Test -> test.Target -> pathTarget.Target -> "target" (int)`) {
+		t.Error("invalid path", err)
+		return
+	}
+	if len(me.Path) != 3 || me.Path[0].Name != "dep" || me.Path[2].Field != "Target" {
+		t.Error("invalid path", me.Path)
+		return
+	}
+	if len(me.Suggestions) != 2 || me.Suggestions[0] != "port" || me.Suggestions[1] != "targets" {
+		t.Error("invalid suggestions", me.Suggestions)
+		return
+	}
+	if _, ok := g.Find("test"); ok {
+		t.Error("test should be rolled back")
+	}
+}
+
+func TestSimilar(t *testing.T) {
+	fmt.Println("############## test similar")
+	cases := []struct {
+		a, b string
+		ok   bool
+	}{
+		{"target", "targets", true},
+		{"Target", "target", true},
+		{"db", "dbMaster", false},
+		{"cache", "userCache", true},
+		{"redis", "mysql", false},
+	}
+	for _, c := range cases {
+		_, ok := similar(c.a, c.b)
+		if ok != c.ok {
+			t.Error("invalid similar", c.a, c.b, ok)
+		}
+	}
+}
+
+var errDial = errors.New("dial fail")
+
+type PrivateInject struct {
+	dep *Dep `inject:""`
+}
+
+type BadStartTimeout struct {
+	Dep *Dep `inject:"" starttimeout:"soon"`
+}
+
+type BadCloseTimeout struct {
+	Dep *Dep `inject:"" closetimeout:"later"`
+}
+
+func TestInvalidRegistration(t *testing.T) {
+	fmt.Println("############## test invalid registration")
+	g := NewTestGraph(t)
+
+	_, err := g.Provide("conn", func() (*Conn, error) {
+		return nil, errDial
+	})
+	if !errors.Is(err, errDial) {
+		t.Error("provider error should be wrapped", err)
+		return
+	}
+
+	invalid := []func() error{
+		func() error { _, err := g.Register("", 1); return err },
+		func() error { _, err := g.Register("nil", (error)(nil)); return err },
+		func() error { _, err := g.Register("private", (*PrivateInject)(nil)); return err },
+		func() error { _, err := g.Provide("p", nil); return err },
+		func() error { _, err := g.Provide("p", func() {}); return err },
+		func() error { _, err := g.RegisterGroup("", &Dep{}); return err },
+		func() error { _, err := g.Register("start", (*BadStartTimeout)(nil)); return err },
+		func() error { _, err := g.Register("close", (*BadCloseTimeout)(nil)); return err },
+		func() error { _, err := g.RegisterWith("dep", &Dep{}, Implements(pluginType)); return err },
+	}
+	for i, fn := range invalid {
+		err := fn()
+		if !errors.Is(err, ErrInvalidRegistration) {
+			t.Error("registration should be invalid", i, err)
+			return
+		}
+	}
+	if g.Len() != 0 {
+		t.Error("nothing should be registered", g.SPrint())
+	}
+}
@@ -0,0 +1,101 @@
+package inji
+
+import (
+	"time"
+)
+
+//EventKind is the kind of a lifecycle event of an object
+type EventKind int
+
+const (
+	//EventBeforeResolve is sent before the dependencies of the object are injected
+	EventBeforeResolve EventKind = iota
+	//EventAfterResolve is sent after the dependencies are injected,
+	//Duration includes the objects created meanwhile
+	EventAfterResolve
+	//EventBeforeStart is sent before Start of the object is called
+	EventBeforeStart
+	//EventAfterStart is sent after Start of the object returns nil
+	EventAfterStart
+	//EventStartFailed is sent after Start of the object fails, instead of EventAfterStart
+	EventStartFailed
+	//EventBeforeClose is sent before Close of the object is called
+	EventBeforeClose
+	//EventAfterClose is sent after Close of the object returns, Err is set if it fails
+	EventAfterClose
+	//EventOverride is sent after the object is registered by Override,
+	//Old is the closed object if any
+	EventOverride
+	//EventReplace is sent after the object is registered by Replace,
+	//Old is the replaced object
+	EventReplace
+	//EventUnregister is sent after the object is removed by Unregister
+	EventUnregister
+)
+
+var eventNames = []string{
+	"before_resolve",
+	"after_resolve",
+	"before_start",
+	"after_start",
+	"start_failed",
+	"before_close",
+	"after_close",
+	"override",
+	"replace",
+	"unregister",
+}
+
+func (k EventKind) String() string {
+	if k < 0 || int(k) >= len(eventNames) {
+		return "unknown"
+	}
+	return eventNames[k]
+}
+
+//Event is a lifecycle event of Object,
+//Duration is set on the After* and failed events
+type Event struct {
+	Kind     EventKind
+	Object   *Object
+	Old      *Object
+	Duration time.Duration
+	Err      error
+}
+
+//OnEvent add fn to listen to the events of objects of graph and its children,
+//events are delivered synchronously on the order they happen and
+//a listener never runs concurrently with another one of the graph,
+//a listener must not call the graph, which can be locked by the registration,
+//a panic of a listener is logged and the event goes on to the others
+func (g *Graph) OnEvent(fn func(Event)) {
+	g.eventL.Lock()
+	defer g.eventL.Unlock()
+	g.listeners = append(g.listeners, fn)
+}
+
+//emit deliver e to the listeners of g, then to the ones of its parents
+func (g *Graph) emit(e Event) {
+	for cur := g; cur != nil; cur = cur.parent {
+		cur.deliver(e)
+	}
+}
+
+//deliver e to the listeners of g only
+func (g *Graph) deliver(e Event) {
+	g.eventL.Lock()
+	defer g.eventL.Unlock()
+	for _, fn := range g.listeners {
+		g.notify(fn, e)
+	}
+}
+
+//notify call fn with e, a panic of fn is logged
+func (g *Graph) notify(fn func(Event), e Event) {
+	defer func() {
+		if x := recover(); x != nil && g.Logger != nil {
+			g.Logger.Error("event listener panic,kind=%v,err=%v", e.Kind, x)
+		}
+	}()
+	fn(e)
+}
@@ -0,0 +1,167 @@
+package inji
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+type EventDB struct {
+}
+
+func (d *EventDB) Start() error {
+	return nil
+}
+
+func (d *EventDB) Close() {
+}
+
+type EventApp struct {
+	DB *EventDB `inject:"db"`
+}
+
+func (a *EventApp) Start() error {
+	return nil
+}
+
+type eventRecorder struct {
+	events []Event
+}
+
+func (r *eventRecorder) on(e Event) {
+	r.events = append(r.events, e)
+}
+
+func (r *eventRecorder) String() string {
+	var s []string
+	for _, e := range r.events {
+		s = append(s, e.Kind.String()+":"+e.Object.Name)
+	}
+	return strings.Join(s, ",")
+}
+
+func TestEvents(t *testing.T) {
+	fmt.Println("############## test events")
+	g := NewGraph()
+	r := &eventRecorder{}
+	g.OnEvent(r.on)
+
+	g.RegisterOrFail("app", (*EventApp)(nil))
+	expected := "before_resolve:app,before_resolve:db,after_resolve:db,before_start:db,after_start:db," +
+		"after_resolve:app,before_start:app,after_start:app"
+	if r.String() != expected {
+		t.Error("invalid register events", r)
+		return
+	}
+	for _, e := range r.events {
+		if strings.HasPrefix(e.Kind.String(), "after") && e.Duration <= 0 {
+			t.Error("after events should have a duration", e)
+			return
+		}
+	}
+
+	r.events = nil
+	err := g.Unregister("app", UnregisterOrphans)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	expected = "unregister:app,before_close:db,after_close:db,unregister:db"
+	if r.String() != expected {
+		t.Error("invalid unregister events", r)
+		return
+	}
+	g.Close()
+}
+
+func TestEventsStartFailed(t *testing.T) {
+	fmt.Println("############## test events start failed")
+	g := NewTestGraph(t)
+	r := &eventRecorder{}
+	g.OnEvent(r.on)
+
+	_, err := g.Register("bad", &BadStarter{})
+	if err == nil {
+		t.Error("bad should fail")
+		return
+	}
+	if r.String() != "before_resolve:bad,after_resolve:bad,before_start:bad,start_failed:bad" {
+		t.Error("invalid start failed events", r)
+		return
+	}
+	if !errors.Is(r.events[3].Err, errBadStart) {
+		t.Error("start failed event should carry the error", r.events[3].Err)
+	}
+}
+
+func TestEventsOverride(t *testing.T) {
+	fmt.Println("############## test events override")
+	g := NewTestGraph(t)
+	g.RegisterOrFail("db", &EventDB{})
+	g.RegisterOrFail("app", (*EventApp)(nil))
+
+	c := g.NewChild()
+	r := &eventRecorder{}
+	g.OnEvent(r.on)
+
+	err := g.Replace("db", &EventDB{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	last := r.events[len(r.events)-1]
+	if last.Kind != EventReplace || last.Object.Name != "db" || last.Old == nil || last.Old == last.Object {
+		t.Error("invalid replace event", r)
+		return
+	}
+
+	r.events = nil
+	err = c.Override("cache", "fake")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if r.String() != "override:cache" || r.events[0].Old != nil {
+		t.Error("events of child should reach parent", r)
+	}
+	c.Close()
+}
+
+func TestEventsListenerPanic(t *testing.T) {
+	fmt.Println("############## test events listener panic")
+	g := NewGraph()
+	l := &errorLog{}
+	g.Logger = l
+	g.OnEvent(func(e Event) {
+		panic("listener panic")
+	})
+	r := &eventRecorder{}
+	g.OnEvent(r.on)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := g.Register("bad", &BadStarter{})
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("bad should fail")
+			return
+		}
+	case <-time.After(5 * time.Second):
+		//g is still locked, leave it
+		t.Error("panic of listener should not deadlock the rollback")
+		return
+	}
+	defer g.Close()
+	if !strings.HasPrefix(r.String(), "before_resolve:bad,after_resolve:bad,before_start:bad,start_failed:bad") {
+		t.Error("other listeners should get the events", r)
+		return
+	}
+	if len(l.errors) < 4 || !strings.Contains(l.errors[0], "listener panic") {
+		t.Error("panics of listener should be logged", l.errors)
+	}
+}
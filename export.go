@@ -0,0 +1,193 @@
+package inji
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+type objectJSON struct {
+	ID    string `json:"id,omitempty"`
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Value string `json:"value,omitempty"`
+	Scope string `json:"scope,omitempty"`
+	//decorators on the order they are applied
+	Decorators []string `json:"decorators,omitempty"`
+}
+
+type keyJSON struct {
+	Key    string     `json:"key"`
+	Object objectJSON `json:"object"`
+}
+
+type edgeJSON struct {
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Field   string `json:"field"`
+	Tag     string `json:"tag"`
+	By      string `json:"by"`
+	Created bool   `json:"created"`
+}
+
+type graphJSON struct {
+	Objects []objectJSON `json:"objects"`
+	Edges   []edgeJSON   `json:"edges"`
+}
+
+func (o *Object) json() objectJSON {
+	j := objectJSON{
+		Name: o.Name,
+		Type: fmt.Sprint(o.reflectType),
+	}
+	if o.reflectType.Kind() == reflect.Ptr {
+		j.Value = fmt.Sprintf("%p", o.Value)
+	}
+	if o.template || o.prototype {
+		j.Scope = ScopePrototype
+	}
+	j.Decorators = o.decorators
+	return j
+}
+
+func (e *Edge) by() string {
+	if e.ByName {
+		return "name"
+	}
+	return "type"
+}
+
+//nodes is every object of graph and the objects injected into them,
+//ids are given on the order of registration
+type nodes struct {
+	objects []*Object
+	ids     map[*Object]string
+}
+
+func (g *Graph) nodes() *nodes {
+	n := &nodes{ids: make(map[*Object]string)}
+	iter := g.named.IterFunc()
+	for kv, ok := iter(); ok; kv, ok = iter() {
+		o, ok := kv.Value.(*Object)
+		if ok {
+			n.add(o)
+		}
+	}
+	//prototypes are not in graph, reach them by edges
+	for i := 0; i < len(n.objects); i++ {
+		for _, e := range n.objects[i].deps {
+			n.add(e.To)
+		}
+	}
+	return n
+}
+
+func (n *nodes) add(o *Object) {
+	if _, ok := n.ids[o]; ok {
+		return
+	}
+	n.ids[o] = fmt.Sprintf("n%d", len(n.objects))
+	n.objects = append(n.objects, o)
+}
+
+//JSON export objects and edges of graph
+func (g *Graph) JSON() ([]byte, error) {
+	g.l.RLock()
+	defer g.l.RUnlock()
+
+	n := g.nodes()
+	j := graphJSON{Objects: []objectJSON{}, Edges: []edgeJSON{}}
+	for _, o := range n.objects {
+		oj := o.json()
+		oj.ID = n.ids[o]
+		j.Objects = append(j.Objects, oj)
+		for _, e := range o.deps {
+			j.Edges = append(j.Edges, edgeJSON{
+				From:    n.ids[o],
+				To:      n.ids[e.To],
+				Field:   e.Field,
+				Tag:     e.Tag,
+				By:      e.by(),
+				Created: e.Created,
+			})
+		}
+	}
+	return json.MarshalIndent(j, "", "  ")
+}
+
+//DOT export graph in graphviz dot language,
+//edges found by type are dashed, auto created ones are bold
+func (g *Graph) DOT() string {
+	g.l.RLock()
+	defer g.l.RUnlock()
+
+	n := g.nodes()
+	buf := bytes.NewBufferString("digraph inji {\n")
+	for _, o := range n.objects {
+		shape := "box"
+		if o.template || o.prototype {
+			shape = "ellipse"
+		}
+		fmt.Fprintf(buf, "\t%s [label=%s shape=%s];\n", n.ids[o], dotQuote(o.Name+"\n"+fmt.Sprint(o.reflectType)), shape)
+	}
+	for _, o := range n.objects {
+		for _, e := range o.deps {
+			var styles []string
+			if !e.ByName {
+				styles = append(styles, "dashed")
+			}
+			if e.Created {
+				styles = append(styles, "bold")
+			}
+			style := ""
+			if len(styles) > 0 {
+				style = fmt.Sprintf(" style=%s", dotQuote(strings.Join(styles, ",")))
+			}
+			fmt.Fprintf(buf, "\t%s -> %s [label=%s%s];\n", n.ids[o], n.ids[e.To], dotQuote(e.Field), style)
+		}
+	}
+	buf.WriteString("}\n")
+	return buf.String()
+}
+
+func dotQuote(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	s = strings.Replace(s, `"`, `\"`, -1)
+	s = strings.Replace(s, "\n", `\n`, -1)
+	return `"` + s + `"`
+}
+
+//Mermaid export graph as a mermaid flowchart,
+//edges found by type are dotted
+func (g *Graph) Mermaid() string {
+	g.l.RLock()
+	defer g.l.RUnlock()
+
+	n := g.nodes()
+	buf := bytes.NewBufferString("flowchart LR\n")
+	for _, o := range n.objects {
+		label := mermaidQuote(o.Name + "<br/>" + fmt.Sprint(o.reflectType))
+		if o.template || o.prototype {
+			fmt.Fprintf(buf, "    %s([%s])\n", n.ids[o], label)
+		} else {
+			fmt.Fprintf(buf, "    %s[%s]\n", n.ids[o], label)
+		}
+	}
+	for _, o := range n.objects {
+		for _, e := range o.deps {
+			arrow := "-->"
+			if !e.ByName {
+				arrow = "-.->"
+			}
+			fmt.Fprintf(buf, "    %s %s|%s| %s\n", n.ids[o], arrow, mermaidQuote(e.Field), n.ids[e.To])
+		}
+	}
+	return buf.String()
+}
+
+func mermaidQuote(s string) string {
+	s = strings.Replace(s, `"`, "#quot;", -1)
+	return `"` + s + `"`
+}
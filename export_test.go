@@ -0,0 +1,81 @@
+package inji
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestExport(t *testing.T) {
+	fmt.Println("############## test export")
+	g := NewGraph()
+	defer g.Close()
+
+	g.RegisterOrFail("conf", "##conf1")
+	i1 := 123
+	g.RegisterOrFail("int1", &i1)
+	g.RegisterOrFail(`te"st2`, (*Test2)(nil))
+	g.RegisterOrFail("protoValue", Proto{})
+	g.RegisterOrFail("u", (*ProtoUser)(nil))
+
+	b, err := g.JSON()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	fmt.Println(string(b))
+	var j graphJSON
+	err = json.Unmarshal(b, &j)
+	if err != nil {
+		t.Error("invalid json", err)
+		return
+	}
+	//conf,int1,te"st2,test1,protoValue,u,proto,protoValue instance
+	if len(j.Objects) != 8 {
+		t.Error("invalid objects", j.Objects)
+		return
+	}
+	ids := make(map[string]objectJSON)
+	for _, o := range j.Objects {
+		ids[o.ID] = o
+	}
+	var test1Edge *edgeJSON
+	for i, e := range j.Edges {
+		if ids[e.From].Name == `te"st2` {
+			test1Edge = &j.Edges[i]
+		}
+	}
+	if test1Edge == nil || test1Edge.Field != "Test1" || test1Edge.By != "type" || !test1Edge.Created {
+		t.Error("invalid te\"st2 edge", test1Edge)
+		return
+	}
+	if ids[test1Edge.To].Type != "*inji.Test1" {
+		t.Error("te\"st2 should depend on test1", ids[test1Edge.To])
+		return
+	}
+
+	dot := g.DOT()
+	fmt.Println(dot)
+	if !strings.HasPrefix(dot, "digraph inji {") || !strings.Contains(dot, `label="te\"st2\n*inji.Test2"`) {
+		t.Error("invalid dot", dot)
+		return
+	}
+	if !strings.Contains(dot, `[label="Test1" style="dashed,bold"]`) || !strings.Contains(dot, `[label="Conf"]`) {
+		t.Error("invalid dot edges", dot)
+		return
+	}
+
+	mermaid := g.Mermaid()
+	fmt.Println(mermaid)
+	if !strings.HasPrefix(mermaid, "flowchart LR") || !strings.Contains(mermaid, "te#quot;st2") || !strings.Contains(mermaid, `-.->|"Test1"|`) {
+		t.Error("invalid mermaid", mermaid)
+		return
+	}
+
+	var entries []keyJSON
+	err = json.Unmarshal([]byte(g.SPrint()), &entries)
+	if err != nil || len(entries) != 6 {
+		t.Error("SPrint should be valid json", err, g.SPrint())
+	}
+}
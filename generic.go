@@ -0,0 +1,73 @@
+package inji
+
+import (
+	"fmt"
+	"reflect"
+)
+
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+func valueOf[T any](o *Object) (T, error) {
+	v, ok := o.value().(T)
+	if !ok {
+		return v, &TypeMismatchError{Name: o.Name, Want: typeOf[T](), Got: o.reflectType}
+	}
+	return v, nil
+}
+
+//Get find the object of type T, e.g.
+//	dep, err := inji.Get[*Dep](g)
+func Get[T any](g *Graph) (T, error) {
+	g.l.RLock()
+	defer g.l.RUnlock()
+	t := typeOf[T]()
+	o, ok := g.findByType(t)
+	if !ok || o == nil {
+		var zero T
+		return zero, fmt.Errorf("%w,type=%v", ErrNotFound, t)
+	}
+	return valueOf[T](o)
+}
+
+//GetNamed find the object named name, it must be of type T
+func GetNamed[T any](g *Graph, name string) (T, error) {
+	g.l.RLock()
+	defer g.l.RUnlock()
+	o, ok := g.find(name)
+	if !ok || o == nil {
+		var zero T
+		return zero, fmt.Errorf("%w,name=%s,type=%v", ErrNotFound, name, typeOf[T]())
+	}
+	return valueOf[T](o)
+}
+
+//MustRegister is the typed g.RegisterOrFail, e.g.
+//	dep := inji.MustRegister(g, "dep", (*Dep)(nil))
+func MustRegister[T any](g *Graph, name string, v T) T {
+	ret := g.RegisterOrFail(name, v)
+	t, ok := ret.(T)
+	if !ok {
+		panic(fmt.Errorf("reg fail,name=%v,err=%w", name, &TypeMismatchError{Name: name, Want: typeOf[T](), Got: reflect.TypeOf(ret)}))
+	}
+	return t
+}
+
+//Provide is the typed g.Provide, fn must return T or (T, error)
+func Provide[T any](g *Graph, name string, fn interface{}, opts ...Option) (T, error) {
+	var zero T
+	ft := reflect.TypeOf(fn)
+	if ft == nil || ft.Kind() != reflect.Func || ft.NumOut() < 1 || ft.Out(0) != typeOf[T]() {
+		return zero, &TypeMismatchError{Name: name, Want: typeOf[T](), Got: ft}
+	}
+	v, err := g.Provide(name, fn, opts...)
+	if err != nil {
+		return zero, err
+	}
+	t, ok := v.(T)
+	if !ok {
+		return zero, &TypeMismatchError{Name: name, Want: typeOf[T](), Got: reflect.TypeOf(v)}
+	}
+	return t, nil
+}
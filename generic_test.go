@@ -0,0 +1,66 @@
+package inji
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestGeneric(t *testing.T) {
+	fmt.Println("############## test generic")
+	g := NewGraph()
+	defer g.Close()
+
+	MustRegister(g, "conf", "##conf1")
+	i1 := 123
+	MustRegister(g, "int1", &i1)
+	t2 := MustRegister(g, "test2", (*Test2)(nil))
+	if t2 == nil || t2.Test1 == nil || t2.Test1.Conf != "##conf1" {
+		t.Error("invalid test2", t2)
+		return
+	}
+
+	t1, err := Get[*Test1](g)
+	if err != nil || t1 != t2.Test1 {
+		t.Error("test1 should be found by type", t1, err)
+		return
+	}
+	conf, err := GetNamed[string](g, "conf")
+	if err != nil || conf != "##conf1" {
+		t.Error("conf should be found by name", conf, err)
+		return
+	}
+
+	_, err = GetNamed[int](g, "conf")
+	var mismatch *TypeMismatchError
+	if !errors.As(err, &mismatch) || mismatch.Name != "conf" {
+		t.Error("conf is not an int", err)
+		return
+	}
+	_, err = GetNamed[string](g, "none")
+	if !errors.Is(err, ErrNotFound) {
+		t.Error("none should not be found", err)
+		return
+	}
+	_, err = Get[*Sin1](g)
+	if !errors.Is(err, ErrNotFound) {
+		t.Error("sin1 should not be found", err)
+		return
+	}
+
+	c, err := Provide[*Conn](g, "conn", func(p struct {
+		Conf string `inject:"conf"`
+	}) *Conn {
+		return &Conn{Addr: p.Conf}
+	})
+	if err != nil || c.Addr != "##conf1" {
+		t.Error("invalid provided conn", c, err)
+		return
+	}
+	_, err = Provide[*Sin1](g, "sin1", func() *Conn {
+		return &Conn{}
+	})
+	if !errors.As(err, &mismatch) {
+		t.Error("provider of *Conn is not a provider of *Sin1", err)
+	}
+}
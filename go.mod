@@ -1,9 +1,10 @@
 module github.com/teou/inji
 
-go 1.13
+go 1.20
 
 require (
 	github.com/facebookgo/structtag v0.0.0-20150214074306-217e25fb9691
 	github.com/teou/implmap v0.0.0-20181215111212-373d77bc2b63
 	github.com/teou/ordered_map v1.0.0
+	gopkg.in/yaml.v3 v3.0.1
 )
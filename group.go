@@ -0,0 +1,132 @@
+package inji
+
+import (
+	"fmt"
+	"reflect"
+)
+
+//InGroup add the object to group, every member of a group
+//is injected into a slice or map field tagged with
+//	`inject:"handlers" group:"true"`
+//slices get the members on the order of registration,
+//maps(keyed by string) get the members keyed by object name
+func InGroup(group string) Option {
+	return func(o *options) {
+		o.groups = append(o.groups, group)
+	}
+}
+
+//RegisterGroup register value as a member of group,
+//it is named as group.index, e.g. handlers.0,
+//the index is the count of members or the next one not taken
+func (g *Graph) RegisterGroup(group string, value interface{}, opts ...Option) (interface{}, error) {
+	g.l.Lock()
+	defer g.l.Unlock()
+	if group == "" {
+		return nil, fmt.Errorf("%w,group can not be empty,type=%v", ErrInvalidRegistration, reflect.TypeOf(value))
+	}
+	name := ""
+	for i := len(g.members(group)); ; i++ {
+		name = fmt.Sprintf("%s.%d", group, i)
+		if _, ok := g.findLocal(name); !ok {
+			break
+		}
+	}
+	opts = append(opts, InGroup(group))
+	return g.transact(func() (interface{}, error) {
+		return g.register(name, value, false, false, newOptions(opts))
+	})
+}
+
+func (g *Graph) RegisterOrFailGroup(group string, value interface{}, opts ...Option) interface{} {
+	v, err := g.RegisterGroup(group, value, opts...)
+	if err != nil {
+		if g.Logger != nil {
+			g.Logger.Error(err)
+		}
+		panic(fmt.Errorf("reg fail,group=%v,err=%w", group, err))
+	}
+	return v
+}
+
+//join add o to its groups
+func (g *Graph) join(o *Object) {
+	if len(o.groups) == 0 {
+		return
+	}
+	if g.groups == nil {
+		g.groups = make(map[string][]*Object)
+	}
+	for _, group := range o.groups {
+		g.groups[group] = append(g.groups[group], o)
+	}
+}
+
+//leaveGroups remove o from its groups
+func (g *Graph) leaveGroups(o *Object) {
+	for _, group := range o.groups {
+		members := g.groups[group]
+		for i, m := range members {
+			if m == o {
+				g.groups[group] = append(members[:i:i], members[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+//Members return the objects of group,
+//members of parent come first
+func (g *Graph) Members(group string) []*Object {
+	g.l.RLock()
+	defer g.l.RUnlock()
+	return g.members(group)
+}
+
+func (g *Graph) members(group string) []*Object {
+	var ret []*Object
+	if g.parent != nil {
+		g.readParent(func(p *Graph) {
+			ret = append(ret, p.members(group)...)
+		})
+	}
+	return append(ret, g.groups[group]...)
+}
+
+//injectGroup inject the members of group into field f of o
+func (g *Graph) injectGroup(o *Object, group string, f reflect.StructField, vf reflect.Value, canNil bool) error {
+	members := g.members(group)
+	if len(members) <= 0 {
+		if canNil {
+			return nil
+		}
+		return g.missing(o, f, group, fmt.Errorf("group=%s is empty", group))
+	}
+
+	t := f.Type
+	switch {
+	case t.Kind() == reflect.Slice:
+		s := reflect.MakeSlice(t, 0, len(members))
+		for i, m := range members {
+			if !m.reflectType.AssignableTo(t.Elem()) {
+				return g.mismatch(o, f, m, nil)
+			}
+			s = reflect.Append(s, reflect.ValueOf(m.value()))
+			o.deps = append(o.deps, &Edge{Field: fmt.Sprintf("%s[%d]", f.Name, i), Tag: group, ByName: true, To: m})
+		}
+		vf.Set(s)
+	case t.Kind() == reflect.Map && t.Key().Kind() == reflect.String:
+		mv := reflect.MakeMapWithSize(t, len(members))
+		for _, m := range members {
+			if !m.reflectType.AssignableTo(t.Elem()) {
+				return g.mismatch(o, f, m, nil)
+			}
+			mv.SetMapIndex(reflect.ValueOf(m.Name).Convert(t.Key()), reflect.ValueOf(m.value()))
+			o.deps = append(o.deps, &Edge{Field: fmt.Sprintf("%s[%s]", f.Name, m.Name), Tag: group, ByName: true, To: m})
+		}
+		vf.Set(mv)
+	default:
+		return fmt.Errorf("%w,group field must be a slice or a map keyed by string,field=%s,type=%v in object %s:%v", ErrInvalidRegistration, f.Name, t, o.Name, o.reflectType)
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package inji
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+type Plugin interface {
+	Name() string
+}
+
+type PluginA struct{}
+
+func (p *PluginA) Name() string {
+	return "a"
+}
+
+type PluginB struct {
+	Conf string `inject:"conf"`
+}
+
+func (p *PluginB) Name() string {
+	return "b:" + p.Conf
+}
+
+type PluginHost struct {
+	List []Plugin          `inject:"plugins" group:"true"`
+	Map  map[string]Plugin `inject:"plugins" group:"true"`
+	None []Plugin          `inject:"none" group:"true" cannil:"true"`
+}
+
+func TestGroup(t *testing.T) {
+	fmt.Println("############## test group")
+	g := NewGraph()
+	defer g.Close()
+
+	g.RegisterOrFail("conf", "##conf1")
+	a := g.RegisterOrFailGroup("plugins", &PluginA{})
+	b, err := g.RegisterWith("pluginB", (*PluginB)(nil), InGroup("plugins"))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	host := g.RegisterOrFail("host", (*PluginHost)(nil)).(*PluginHost)
+	if len(host.List) != 2 || host.List[0] != a || host.List[1] != b {
+		t.Error("slice should get members on registration order", host.List)
+		return
+	}
+	if len(host.Map) != 2 || host.Map["plugins.0"] != a || host.Map["pluginB"].Name() != "b:##conf1" {
+		t.Error("map should get members keyed by name", host.Map)
+		return
+	}
+	if host.None != nil {
+		t.Error("empty group with cannil should be nil", host.None)
+		return
+	}
+	if len(g.Members("plugins")) != 2 {
+		t.Error("invalid members", g.Members("plugins"))
+		return
+	}
+
+	child := g.NewChild()
+	c := child.RegisterOrFailGroup("plugins", &PluginA{})
+	childHost := child.RegisterOrFail("host", (*PluginHost)(nil)).(*PluginHost)
+	if len(childHost.List) != 3 || childHost.List[2] != c || len(childHost.Map) != 3 {
+		t.Error("child should get members of parent first", childHost.List, childHost.Map)
+		return
+	}
+	if len(g.Members("plugins")) != 2 {
+		t.Error("child members should not leak into parent")
+		return
+	}
+	child.Close()
+
+	type emptyHost struct {
+		List []Plugin `inject:"none" group:"true"`
+	}
+	_, err = g.Register("empty", (*emptyHost)(nil))
+	if err == nil {
+		t.Error("empty group should fail")
+		return
+	}
+	fmt.Println(err)
+
+	type invalidHost struct {
+		List []*PluginA `inject:"plugins" group:"true"`
+	}
+	_, err = g.Register("invalid", (*invalidHost)(nil))
+	if err == nil {
+		t.Error("*PluginB is not a *PluginA")
+		return
+	}
+	fmt.Println(err)
+}
+
+func TestGroupNameAfterUnregister(t *testing.T) {
+	fmt.Println("############## test group name after unregister")
+	g := NewTestGraph(t)
+	g.RegisterOrFailGroup("hs", &PluginA{})
+	g.RegisterOrFailGroup("hs", &PluginA{})
+
+	err := g.Unregister("hs.0", UnregisterRefuse)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, err = g.RegisterGroup("hs", &PluginA{})
+	if err != nil {
+		t.Error("a free name should be used", err)
+		return
+	}
+	if _, ok := g.Find("hs.2"); !ok || g.Len() != 2 {
+		t.Error("new member should be hs.2", g.SPrint())
+	}
+}
+
+type BrokenPlugin struct{}
+
+func (p *BrokenPlugin) Name() string {
+	return "broken"
+}
+
+func (p *BrokenPlugin) Close() error {
+	return errors.New("broken plugin")
+}
+
+func TestGroupAfterClose(t *testing.T) {
+	fmt.Println("############## test group after close")
+	g := NewGraph()
+	g.RegisterOrFailGroup("plugins", &PluginA{})
+	broken := g.RegisterOrFailGroup("plugins", &BrokenPlugin{})
+
+	err := g.Close()
+	if err == nil {
+		t.Error("broken plugin should fail to close")
+		return
+	}
+	members := g.Members("plugins")
+	if len(members) != 1 || members[0].Value != broken {
+		t.Error("closed members should leave the group", members)
+		return
+	}
+	_, err = g.RegisterGroup("plugins", &PluginA{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(g.Members("plugins")) != 2 {
+		t.Error("group should get the new member", g.Members("plugins"))
+	}
+}
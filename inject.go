@@ -4,10 +4,15 @@ by liyibo 2016
 instance in memory, we set twice in named to
 ensure that(first by name, then by struct ptr type)
 
-2.TODO every struct inject object(not a ptr) should be handled like:
-create new struct on every inject
-	`inject:""` by type,must be a pointer to a struct
+2.every struct inject object(not a ptr) is handled like
+spring's prototype scope: a new struct is created on every inject
+and it is never set into graph.named,
+a registered struct value is used as the template of the new struct,
+without a tag a zero struct is used when no template is registered
 	`inject:"devService"`
+	`inject:""`
+struct ptr injections can be made prototype by tag too
+	`inject:"devService" scope:"prototype"`
 
 3.singleton(default false)
 	`singleton:"true"`
@@ -16,14 +21,37 @@ create new struct on every inject
 4.cannil(default false)
 	`cannil:"true"`
 	`cannil:"false"`
+
+5.start and close timeout of the auto created object(default graph's)
+	`starttimeout:"3s"`
+	`closetimeout:"1s"`
+
+6.group, inject every member of a group into a slice or map
+	`inject:"handlers" group:"true"`
+
+7.config, inject a value loaded by LoadConfig, converted to the field type
+	`config:"http.timeout" default:"5s"`
+
+8.default, used when the dependency is not found,
+${name} and ${name:default} placeholders are resolved by objects and config,
+so are the ones in registered strings, $${ is kept as a literal ${
+	`inject:"path" default:"${home}/data"`
+
+9.dynamic value, follow the changes made by Graph.Update
+	Timeout *inji.Value[time.Duration] `inject:"timeout"`
+
+10.lazy, create and start the dependency on the first Get
+	Client inji.Lazy[*Client] `inject:""`
+	Conn inji.Provider[*Conn] `inject:"" scope:"prototype"`
 **/
 package inji
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
 	"reflect"
 	"strings"
 	"sync"
@@ -41,25 +69,115 @@ type Logger interface {
 	Error(format interface{}, v ...interface{}) error
 }
 
+const (
+	ScopeSingleton = "singleton"
+	ScopePrototype = "prototype"
+)
+
 type Object struct {
 	Name        string
 	reflectType reflect.Type
 	Value       interface{}
 	closed      bool
+	//template is a struct registered by value,
+	//only copies of it are injected
+	template bool
+	//prototype is created for one inject and never set into graph.named
+	prototype bool
+	//prototypes created while injecting this object, closed with it
+	instances []*Object
+	//objects injected into this object
+	deps []*Edge
+	//groups the object is a member of
+	groups []string
+	//interfaces the object is bound to
+	implements []reflect.Type
+	//*Value[T] fields following this object, guarded by watchL
+	watchers []watcher
+	//start is deferred to the end of registration and not done yet
+	deferred bool
+	//Value wrapped by decorators, injected instead of Value
+	decorated  interface{}
+	decorators []string
+	//registered by Override, later registrations of the name are ignored
+	override bool
+	//time spent on resolving dependencies(objects created meanwhile excluded),
+	//on Start and on Close, see StartupReport
+	resolveTime time.Duration
+	startTime   time.Duration
+	closeTime   time.Duration
+
+	startTimeout time.Duration
+	closeTimeout time.Duration
 }
 
 func (o Object) String() string {
-	if o.reflectType.Kind() == reflect.Ptr {
-		return fmt.Sprintf(`{"name":"%s","type":"%v","value":"%p"}`, o.Name, o.reflectType, o.Value)
-	} else {
-		return fmt.Sprintf(`{"name":"%s","type":"%v"}`, o.Name, o.reflectType)
-	}
+	b, _ := json.Marshal(o.json())
+	return string(b)
+}
+
+//Deps return the edges from o to the objects injected into it,
+//on the order of o's fields
+func (o *Object) Deps() []*Edge {
+	return o.deps
+}
+
+//Edge is a field of an object injected with another object
+type Edge struct {
+	Field string
+	Tag   string
+	//ByName is true when To is found by Tag, otherwise by type
+	ByName bool
+	//Created is true when To is auto created for this injection
+	Created bool
+	To      *Object
 }
 
 type Graph struct {
 	l      sync.RWMutex
 	Logger Logger
 	named  *ordered_map.OrderedMap
+	//bound every Start and Close when not set by the object, 0 means no limit
+	DefaultStartTimeout time.Duration
+	DefaultCloseTimeout time.Duration
+	//objects being injected now, used to find dependency cycles
+	resolving []*resolveFrame
+	//the running top level registration
+	tx *regTx
+	//objects not found in graph are found in parent
+	parent *Graph
+	//set for a child created by Resolver.NewChild, parent is read
+	//through it while the Start of the resolver is running
+	via *Resolver
+	//members of groups on the order of registration
+	groups map[string][]*Object
+	//implementations bound to interfaces
+	bindings map[reflect.Type][]binding
+	//values loaded by LoadConfig
+	config map[string]interface{}
+	//converters registered by RegisterConverter
+	converters map[convertKey]ConvertFunc
+	//when > 0, objects created by one registration are started after
+	//all of them are injected, by at most StartWorkers goroutines,
+	//an object is still started after its dependencies
+	StartWorkers int
+	timelineL    sync.Mutex
+	timeline     []StartSpan
+	//held by a Resolver used inside Start, while g.l is held
+	//by the registration starting the object
+	reentry   sync.Mutex
+	reentered int
+	//resolvers of the objects being started, used by their Lazy fields
+	startingL sync.Mutex
+	starting  map[*Object]*Resolver
+	//decorators on the order of Decorate calls
+	decorators []decorator
+	//a Start taking longer is logged as an error by Logger,
+	//0 means 5s, a negative value disables the log
+	SlowStartThreshold time.Duration
+	//listeners added by OnEvent, eventL also serializes the delivery
+	eventL    sync.Mutex
+	listeners []func(Event)
 }
 
 func NewGraph() *Graph {
@@ -69,6 +187,22 @@ func NewGraph() *Graph {
 	return g
 }
 
+//NewChild create a graph which finds objects in g
+//when they are not found in the child itself,
+//objects registered into the child never leak into g,
+//and closing the child only close its own objects,
+//lookups of the child read lock g, use Resolver.NewChild in Start
+func (g *Graph) NewChild() *Graph {
+	c := NewGraph()
+	c.parent = g
+	c.Logger = g.Logger
+	c.DefaultStartTimeout = g.DefaultStartTimeout
+	c.DefaultCloseTimeout = g.DefaultCloseTimeout
+	c.StartWorkers = g.StartWorkers
+	c.SlowStartThreshold = g.SlowStartThreshold
+	return c
+}
+
 func getTypeName(t reflect.Type) string {
 	isptr := false
 	if t.Kind() == reflect.Ptr {
@@ -96,7 +230,12 @@ func (g *Graph) FindByType(t reflect.Type) (*Object, bool) {
 
 func (g *Graph) findByType(t reflect.Type) (*Object, bool) {
 	n := getTypeName(t)
-	return g.find(n)
+	o, ok := g.find(n)
+	if !ok && t.Kind() == reflect.Interface {
+		o, _, err := g.findBound(t, false)
+		return o, err == nil
+	}
+	return o, ok
 }
 
 func (g *Graph) Len() int {
@@ -116,6 +255,17 @@ func (g *Graph) Find(name string) (*Object, bool) {
 }
 
 func (g *Graph) find(name string) (*Object, bool) {
+	o, ok := g.findLocal(name)
+	if !ok && g.parent != nil {
+		g.readParent(func(p *Graph) {
+			o, ok = p.find(name)
+		})
+	}
+	return o, ok
+}
+
+//findLocal find name in graph only, never in parent
+func (g *Graph) findLocal(name string) (*Object, bool) {
 	f, ok := g.named.Get(name)
 	if !ok {
 		return nil, false
@@ -124,7 +274,6 @@ func (g *Graph) find(name string) (*Object, bool) {
 	if !ok {
 		//g.named.Delete(name)
 		panic(fmt.Sprintf("%s in graph is not a *Object, should not happen!", name))
-		return nil, false
 	} else {
 		return ret, true
 	}
@@ -156,7 +305,7 @@ func (g *Graph) RegisterOrFailNoFill(name string, value interface{}) interface{}
 		if g.Logger != nil {
 			g.Logger.Error(err)
 		}
-		panic(fmt.Sprintf("reg fail,name=%v,err=%v", name, err.Error()))
+		panic(fmt.Errorf("reg fail,name=%v,err=%w", name, err))
 	}
 	return v
 }
@@ -167,7 +316,7 @@ func (g *Graph) RegisterOrFailSingleNoFill(name string, value interface{}) inter
 		if g.Logger != nil {
 			g.Logger.Error(err)
 		}
-		panic(fmt.Sprintf("reg fail,name=%v,err=%v", name, err.Error()))
+		panic(fmt.Errorf("reg fail,name=%v,err=%w", name, err))
 	}
 	return v
 }
@@ -178,7 +327,7 @@ func (g *Graph) RegisterOrFail(name string, value interface{}) interface{} {
 		if g.Logger != nil {
 			g.Logger.Error(err)
 		}
-		panic(fmt.Sprintf("reg fail,name=%v,err=%v", name, err.Error()))
+		panic(fmt.Errorf("reg fail,name=%v,err=%w", name, err))
 	}
 	return v
 }
@@ -189,7 +338,7 @@ func (g *Graph) RegisterOrFailSingle(name string, value interface{}) interface{}
 		if g.Logger != nil {
 			g.Logger.Error(err)
 		}
-		panic(fmt.Sprintf("reg fail,name=%v,err=%v", name, err.Error()))
+		panic(fmt.Errorf("reg fail,name=%v,err=%w", name, err))
 	}
 	return v
 }
@@ -197,28 +346,48 @@ func (g *Graph) RegisterOrFailSingle(name string, value interface{}) interface{}
 func (g *Graph) RegisterNoFill(name string, value interface{}) (interface{}, error) {
 	g.l.Lock()
 	defer g.l.Unlock()
-	return g.register(name, value, false, true)
+	return g.transact(func() (interface{}, error) {
+		return g.register(name, value, false, true, options{})
+	})
 }
 
 func (g *Graph) RegisterSingleNoFill(name string, value interface{}) (interface{}, error) {
 	g.l.Lock()
 	defer g.l.Unlock()
-	return g.register(name, value, true, true)
+	return g.transact(func() (interface{}, error) {
+		return g.register(name, value, true, true, options{})
+	})
 }
 
 func (g *Graph) Register(name string, value interface{}) (interface{}, error) {
 	g.l.Lock()
 	defer g.l.Unlock()
-	return g.register(name, value, false, false)
+	return g.transact(func() (interface{}, error) {
+		return g.register(name, value, false, false, options{})
+	})
+}
+
+//RegisterWith is Register with options of the object
+func (g *Graph) RegisterWith(name string, value interface{}, opts ...Option) (interface{}, error) {
+	g.l.Lock()
+	defer g.l.Unlock()
+	return g.transact(func() (interface{}, error) {
+		return g.register(name, value, false, false, newOptions(opts))
+	})
 }
 
 func (g *Graph) RegisterSingle(name string, value interface{}) (interface{}, error) {
 	g.l.Lock()
 	defer g.l.Unlock()
-	return g.register(name, value, true, false)
+	return g.transact(func() (interface{}, error) {
+		return g.register(name, value, true, false, options{})
+	})
 }
 
-func (g *Graph) register(name string, value interface{}, singleton bool, noFill bool) (interface{}, error) {
+func (g *Graph) register(name string, value interface{}, singleton bool, noFill bool, opts options) (interface{}, error) {
+	if value == nil {
+		return nil, fmt.Errorf("%w,register nil on name=%s", ErrInvalidRegistration, name)
+	}
 	reflectType := reflect.TypeOf(value)
 
 	if isStructPtr(reflectType) {
@@ -227,20 +396,24 @@ func (g *Graph) register(name string, value interface{}, singleton bool, noFill
 		}
 	} else {
 		if name == "" {
-			return nil, fmt.Errorf("name can not be empty,name=%s,type=%v", name, reflectType)
+			return nil, fmt.Errorf("%w,name can not be empty,name=%s,type=%v", ErrInvalidRegistration, name, reflectType)
 		}
 	}
 
-	//already registered
-	found, ok := g.find(name)
+	//already registered, objects of parent can be hidden
+	found, ok := g.findLocal(name)
+	if ok && found.override {
+		return found.Value, nil
+	}
 	if ok {
-		return nil, fmt.Errorf("already registered,name=%s,type=%v,found=%v", name, reflectType, found)
+		return nil, fmt.Errorf("%w,name=%s,type=%v,found=%v", ErrAlreadyRegistered, name, reflectType, found)
 	}
 
 	o := &Object{
 		Name:        name,
 		reflectType: reflectType,
 	}
+	opts.apply(o)
 	if isStructPtr(o.reflectType) {
 		t := reflectType.Elem()
 		var v reflect.Value
@@ -252,199 +425,424 @@ func (g *Graph) register(name string, value interface{}, singleton bool, noFill
 			v = reflect.ValueOf(value)
 		}
 
-		for i := 0; i < t.NumField(); i++ {
-			if !created && noFill {
-				continue
+		if created || !noFill {
+			err := g.inject(o, v, noFill)
+			if err != nil {
+				return nil, err
+			}
+		}
+		o.Value = v.Interface()
+	} else if reflectType.Kind() == reflect.Struct {
+		//a struct(not a pointer) is a prototype template,
+		//a new copy of it is created and injected on every inject,
+		//see instantiate
+		o.Value = value
+		o.template = true
+	} else {
+		if canNil(value) && isNil(value) {
+			return nil, fmt.Errorf("%w,register nil on name=%s, val=%v", ErrInvalidRegistration, name, value)
+		}
+		if reflectType.Kind() == reflect.String {
+			expanded, err := g.expand(reflect.ValueOf(value).String())
+			if err != nil {
+				return nil, fmt.Errorf("expand placeholders fail,name=%s,err=%w", name, err)
 			}
+			value = reflect.ValueOf(expanded).Convert(reflectType).Interface()
+		}
+		o.Value = value
+	}
 
-			f := t.Field(i)
-			vfe := v.Elem()
-			vf := vfe.Field(i)
+	err := g.checkImplements(o)
+	if err != nil {
+		return nil, err
+	}
 
-			ok, tag, err := structtag.Extract("inject", string(f.Tag))
-			if err != nil {
-				return nil, fmt.Errorf("extract tag fail,f=%s,err=%v", f.Name, err)
+	//depedency resolved, init the object
+	if !o.template {
+		err := g.startOrDefer(o)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	//set to graph
+	if isStructPtr(reflectType) && singleton {
+		g.setboth(name, o)
+	} else {
+		g.set(name, o)
+	}
+	g.join(o)
+	g.bindImplements(o)
+	g.track(o)
+	if !o.template {
+		err := g.decorate(o)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if g.Logger != nil && g.Logger.IsDebugEnabled() {
+		toLogJson, toLogErr := json.Marshal(o.Value)
+		g.Logger.Debug("registered!name=%s,t=%v,v=%v,jsonerr=%v", name, reflectType, string(toLogJson), toLogErr)
+	}
+	return o.Value, nil
+}
+
+//inject fill every inject field of v(a struct pointer) owned by o
+func (g *Graph) inject(o *Object, v reflect.Value, noFill bool) (err error) {
+	frame, err := g.enter(o.Name, o.reflectType)
+	if err != nil {
+		return err
+	}
+	g.emit(Event{Kind: EventBeforeResolve, Object: o})
+	begin := time.Now()
+	defer func() {
+		g.leave()
+		g.resolved(o, frame, begin)
+		g.emit(Event{Kind: EventAfterResolve, Object: o, Duration: time.Since(begin), Err: err})
+	}()
+	return g.fill(o, frame, v, noFill)
+}
+
+//fill the inject fields of v for o, frame is the resolving frame of o
+func (g *Graph) fill(o *Object, frame *resolveFrame, v reflect.Value, noFill bool) error {
+	t := v.Type().Elem()
+	vfe := v.Elem()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		vf := vfe.Field(i)
+		frame.field = f.Name
+
+		_, key, _ := structtag.Extract("config", string(f.Tag))
+		if key != "" {
+			if !vf.CanInterface() || isZeroOfUnderlyingType(vf.Interface()) {
+				err := g.injectConfig(o, key, f, vf)
+				if err != nil {
+					return err
+				}
 			}
-			if !ok {
+			continue
+		}
+
+		ok, tag, err := structtag.Extract("inject", string(f.Tag))
+		if err != nil {
+			return fmt.Errorf("%w,extract tag fail,f=%s,err=%v", ErrInvalidRegistration, f.Name, err)
+		}
+		if !ok {
+			continue
+		}
+
+		if vf.CanInterface() {
+			if !isZeroOfUnderlyingType(vf.Interface()) {
 				continue
 			}
+		}
 
-			if vf.CanInterface() {
-				if !isZeroOfUnderlyingType(vf.Interface()) {
-					continue
-				}
+		if f.Anonymous || !vf.CanSet() {
+			return fmt.Errorf("%w,inject tag must on a public field!field=%s,type=%s", ErrInvalidRegistration, f.Name, t.Name())
+		}
+
+		_, singletonStr, _ := structtag.Extract("singleton", string(f.Tag))
+		singletonTag := false
+		if singletonStr == "true" {
+			singletonTag = true
+		}
+		_, canNilStr, _ := structtag.Extract("cannil", string(f.Tag))
+		_, nilableStr, _ := structtag.Extract("nilable", string(f.Tag))
+		canNil := false
+		if canNilStr == "true" || nilableStr == "true" {
+			canNil = true
+		}
+		_, groupStr, _ := structtag.Extract("group", string(f.Tag))
+		if groupStr == "true" {
+			err := g.injectGroup(o, tag, f, vf, canNil)
+			if err != nil {
+				return err
 			}
+			continue
+		}
+		if isLazyField(f.Type) {
+			g.injectLazy(o, f, vf)
+			continue
+		}
+		if f.Type.Kind() == reflect.Ptr && f.Type.Implements(dynamicType) {
+			err := g.injectDynamic(o, tag, f, vf, canNil)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		_, scopeStr, _ := structtag.Extract("scope", string(f.Tag))
+		opts, err := tagOptions(f)
+		if err != nil {
+			return err
+		}
 
-			if f.Anonymous || !vf.CanSet() {
-				return nil, fmt.Errorf("inject tag must on a public field!field=%s,type=%s", f.Name, t.Name())
-				// continue // useless code
+		var found *Object
+		if tag != "" {
+			//due to default singleton of struct ptr injections
+			//we should first find by name,then find by type
+			found, ok = g.find(tag)
+			if singletonTag && !ok && isStructPtr(f.Type) {
+				found, ok = g.findByType(f.Type)
 			}
+		} else {
+			found, ok = g.findByType(f.Type)
+		}
 
-			_, singletonStr, _ := structtag.Extract("singleton", string(f.Tag))
-			singletonTag := false
-			if singletonStr == "true" {
-				singletonTag = true
+		if scopeStr == ScopePrototype {
+			//a prototype field never shares an object,
+			//only a template can be used as its initial value
+			if !ok || !found.template {
+				found = nil
 			}
-			_, canNilStr, _ := structtag.Extract("cannil", string(f.Tag))
-			_, nilableStr, _ := structtag.Extract("nilable", string(f.Tag))
-			canNil := false
-			if canNilStr == "true" || nilableStr == "true" {
-				canNil = true
+			err := g.instantiate(o, found, tag, f, vf, noFill, opts)
+			if err != nil {
+				return err
 			}
+			continue
+		}
 
-			var found *Object
-			if tag != "" {
-				//due to default singleton of struct ptr injections
-				//we should first find by name,then find by type
-				found, ok = g.find(tag)
-				if singletonTag && !ok && isStructPtr(f.Type) {
-					found, ok = g.findByType(f.Type)
+		created := false
+		if (!ok || found == nil) && tag == "" && f.Type.Kind() == reflect.Interface && len(g.bound(f.Type)) > 0 {
+			found, created, err = g.findBound(f.Type, true)
+			if err != nil {
+				return g.missing(o, f, tag, err)
+			}
+			ok = true
+		}
+		if !ok || found == nil {
+			hasDefault, def, _ := structtag.Extract("default", string(f.Tag))
+			if hasDefault {
+				err := g.injectDefault(o, def, f, vf)
+				if err != nil {
+					return err
 				}
-			} else {
-				found, ok = g.findByType(f.Type)
+				continue
 			}
-
-			if !ok || found == nil {
-				if canNil {
-					continue
+			if canNil {
+				continue
+			}
+			if f.Type.Kind() == reflect.Struct && tag == "" {
+				//a struct field found by type is a prototype even without a template,
+				//a named one still needs the template of its name
+				err := g.instantiate(o, nil, tag, f, vf, noFill, opts)
+				if err != nil {
+					return err
 				}
-				if isStructPtr(f.Type) {
-					_, err := g.register(tag, reflect.NewAt(f.Type.Elem(), nil).Interface(), singletonTag, noFill)
-					if err != nil {
-						return nil, err
+				continue
+			}
+			created = true
+			if isStructPtr(f.Type) {
+				_, err := g.register(tag, reflect.NewAt(f.Type.Elem(), nil).Interface(), singletonTag, noFill, opts)
+				if err != nil {
+					return err
+				}
+			} else {
+				var implFound reflect.Type
+				impls := implmap.Get(tag)
+				for _, impl := range impls {
+					if impl == nil {
+						continue
 					}
-				} else {
-					var implFound reflect.Type
-					impls := implmap.Get(tag)
-					for _, impl := range impls {
-						if impl == nil {
-							continue
-						}
-						if impl.AssignableTo(f.Type) {
-							implFound = impl
-							break
-						}
-
+					if impl.AssignableTo(f.Type) {
+						implFound = impl
+						break
 					}
 
-					if implFound != nil {
-						_, err := g.register(tag, reflect.NewAt(implFound.Elem(), nil).Interface(), singletonTag, noFill)
-						if err != nil {
-							return nil, err
-						}
-					} else {
-						return nil, fmt.Errorf("dependency field=%s,tag=%s not found in object %s:%v", f.Name, tag, name, reflectType)
-					}
 				}
 
-				if tag != "" {
-					found, ok = g.find(tag)
-					if !ok && singleton {
-						found, ok = g.findByType(f.Type)
+				if implFound != nil {
+					_, err := g.register(tag, reflect.NewAt(implFound.Elem(), nil).Interface(), singletonTag, noFill, opts)
+					if err != nil {
+						return err
 					}
 				} else {
-					found, ok = g.findByType(f.Type)
+					return g.missing(o, f, tag, nil)
 				}
 			}
 
-			if !ok || found == nil {
-				return nil, fmt.Errorf("dependency %s not found in object %s:%v", f.Name, name, reflectType)
-			}
-
-			reflectFoundValue := reflect.ValueOf(found.Value)
-			if !found.reflectType.AssignableTo(f.Type) {
-				switch reflectFoundValue.Kind() {
-				case reflect.Int:
-					fallthrough
-				case reflect.Int8:
-					fallthrough
-				case reflect.Int16:
-					fallthrough
-				case reflect.Int32:
-					fallthrough
-				case reflect.Int64:
-					iv := reflectFoundValue.Int()
-					switch f.Type.Kind() {
-					case reflect.Int:
-						fallthrough
-					case reflect.Int8:
-						fallthrough
-					case reflect.Int16:
-						fallthrough
-					case reflect.Int32:
-						fallthrough
-					case reflect.Int64:
-						vf.SetInt(iv)
-					default:
-						return nil, fmt.Errorf("dependency name=%s,type=%v not valid in object %s:%v", f.Name, f.Type, name, reflectType)
-					}
-				case reflect.Float32:
-					fallthrough
-				case reflect.Float64:
-					fv := reflectFoundValue.Float()
-					switch f.Type.Kind() {
-					case reflect.Float32:
-						fallthrough
-					case reflect.Float64:
-						vf.SetFloat(fv)
-					default:
-						return nil, fmt.Errorf("dependency name=%s,type=%v not valid in object %s:%v", f.Name, f.Type, name, reflectType)
-					}
-				default:
-					return nil, fmt.Errorf("dependency name=%s,type=%v not valid in object %s:%v", f.Name, f.Type, name, reflectType)
+			if tag != "" {
+				found, ok = g.find(tag)
+				if !ok && singletonTag {
+					found, ok = g.findByType(f.Type)
 				}
 			} else {
-				vf.Set(reflectFoundValue)
+				found, ok = g.findByType(f.Type)
 			}
 		}
-		o.Value = v.Interface()
-	} else {
-		//TODO
-		//if inejection type is a struct(not a pointer),
-		//we should create a new struct every time
-		//when a inject tag is found,and no *Object is created and
-		//the created struct should NOT be set into
-		//graph.named(or there will be a memory leak)!
-		//same as a bean's prototype scope in spring.
-		//otherwise all inject dependency will behave like
-		//spring's singleton bean scope
-		if canNil(value) && isNil(value) {
-			return nil, fmt.Errorf("register nil on name=%s, val=%v", name, value)
+
+		if !ok || found == nil {
+			return g.missing(o, f, tag, nil)
 		}
-		o.Value = value
-	}
 
-	//depedency resolved, init the object
-	canStart, ok := o.Value.(Startable)
-	if ok {
-		st := time.Now()
-		err := canStart.Start()
-		cost := time.Now().Sub(st)
-
-		if cost > 5*time.Second {
-			errMsg := fmt.Sprintf("obj start took too long,name=%v,time=%v,err=%v", name, cost, err)
-			fmt.Fprint(os.Stderr, errMsg+"\n")
-			if g.Logger != nil {
-				g.Logger.Error(errMsg)
+		if found.template {
+			err := g.instantiate(o, found, tag, f, vf, noFill, opts)
+			if err != nil {
+				return err
 			}
+			continue
 		}
 
+		v, err := g.convert(reflect.ValueOf(found.value()), f.Type)
 		if err != nil {
-			return nil, fmt.Errorf("Start object fail,name=%v,err=%v", name, err)
+			return g.mismatch(o, f, found, err)
 		}
+		vf.Set(v)
+		o.deps = append(o.deps, &Edge{
+			Field:   f.Name,
+			Tag:     tag,
+			ByName:  tag != "" && found.Name == tag,
+			Created: created,
+			To:      found,
+		})
 	}
+	return nil
+}
 
-	//set to graph
-	if isStructPtr(reflectType) && singleton {
-		g.setboth(name, o)
+//instantiate create a new struct for a prototype field f of o,
+//tpl(if any) is copied as the initial value of the new struct,
+//the new struct is injected and started, then tracked by o
+//so it can be closed with o, it is never set into graph.named
+func (g *Graph) instantiate(o *Object, tpl *Object, name string, f reflect.StructField, vf reflect.Value, noFill bool, opts options) error {
+	t := f.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return fmt.Errorf("%w,prototype must be a struct or struct ptr,field=%s,type=%v in object %s:%v", ErrInvalidRegistration, f.Name, f.Type, o.Name, o.reflectType)
+	}
+	tag := name
+	if name == "" {
+		name = getTypeName(f.Type)
+	}
+
+	v := reflect.New(t)
+	if tpl != nil {
+		tv := reflect.ValueOf(tpl.Value)
+		if !tv.Type().AssignableTo(t) {
+			return g.mismatch(o, f, tpl, nil)
+		}
+		v.Elem().Set(tv)
+	}
+
+	p := &Object{
+		Name:        name,
+		reflectType: v.Type(),
+		prototype:   true,
+	}
+	opts.apply(p)
+	err := g.inject(p, v, noFill)
+	if err != nil {
+		return err
+	}
+	if f.Type.Kind() == reflect.Ptr {
+		vf.Set(v)
 	} else {
-		g.set(name, o)
+		//start and close the struct living in the field,
+		//not the one we have just copied from
+		vf.Set(v.Elem())
+		v = vf.Addr()
 	}
-	if g.Logger != nil && g.Logger.IsDebugEnabled() {
-		toLogJson, toLogErr := json.Marshal(o.Value)
-		g.Logger.Debug("registered!name=%s,t=%v,v=%v,jsonerr=%v", name, reflectType, string(toLogJson), toLogErr)
+	p.Value = v.Interface()
+
+	err = g.startOrDefer(p)
+	if err != nil {
+		return err
+	}
+	o.instances = append(o.instances, p)
+	o.deps = append(o.deps, &Edge{
+		Field:   f.Name,
+		Tag:     tag,
+		ByName:  tpl != nil,
+		Created: true,
+		To:      p,
+	})
+	g.track(p)
+	return nil
+}
+
+func (g *Graph) start(o *Object, worker int) error {
+	var run func(ctx context.Context) error
+	switch s := o.Value.(type) {
+	case StartableContext:
+		run = s.Start
+	case Startable:
+		run = func(context.Context) error {
+			return s.Start()
+		}
+	default:
+		return nil
+	}
+
+	timeout := o.startTimeout
+	if timeout <= 0 {
+		timeout = g.DefaultStartTimeout
+	}
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	g.emit(Event{Kind: EventBeforeStart, Object: o})
+	r := g.newResolver(worker)
+	ctx = context.WithValue(ctx, resolverKey{}, r)
+	g.setStarting(o, r)
+	st := time.Now()
+	err := func() error {
+		defer func() {
+			r.close()
+			g.setStarting(o, nil)
+		}()
+		return runContext(ctx, run)
+	}()
+	end := time.Now()
+	cost := end.Sub(st)
+	g.record(StartSpan{Name: o.Name, Worker: worker, Begin: st, End: end, Err: err})
+	g.started(o, worker, cost)
+	if err != nil {
+		g.emit(Event{Kind: EventStartFailed, Object: o, Duration: cost, Err: err})
+	} else {
+		g.emit(Event{Kind: EventAfterStart, Object: o, Duration: cost})
+	}
+
+	threshold := g.SlowStartThreshold
+	if threshold == 0 {
+		threshold = 5 * time.Second
+	}
+	if threshold > 0 && cost > threshold && g.Logger != nil {
+		g.Logger.Error("obj start took too long,name=%v,time=%v,threshold=%v,err=%v", o.Name, cost, threshold, err)
+	}
+
+	if err != nil {
+		return &StartError{Name: o.Name, Type: o.reflectType, Err: err}
+	}
+	return nil
+}
+
+//runContext return when fn returns or ctx is done,
+//fn keeps running in background if it does not respect ctx,
+//a panic of fn running in background is returned as an error
+func runContext(ctx context.Context, fn func(ctx context.Context) error) error {
+	if ctx.Done() == nil {
+		return fn(ctx)
+	}
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if x := recover(); x != nil {
+				done <- fmt.Errorf("panic:%v", x)
+			}
+		}()
+		done <- fn(ctx)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
 	}
-	return o.Value, nil
 }
 
 func (g *Graph) SPrint() string {
@@ -454,21 +852,17 @@ func (g *Graph) SPrint() string {
 }
 
 func (g *Graph) sPrint() string {
-	ret := "["
+	entries := []keyJSON{}
 	iter := g.named.IterFunc()
-	count := g._len()
-	i := 0
 	for kv, ok := iter(); ok; kv, ok = iter() {
-		str := fmt.Sprintf(`{"key":"%s","object":%s}`, fmt.Sprintf("%s", kv.Key), fmt.Sprintf("%s", kv.Value))
-
-		ret = ret + str
-		i++
-		if i < count {
-			ret = ret + ","
+		o, ok := kv.Value.(*Object)
+		if !ok {
+			continue
 		}
+		entries = append(entries, keyJSON{Key: fmt.Sprint(kv.Key), Object: o.json()})
 	}
-	ret = ret + "]"
-	return ret
+	b, _ := json.Marshal(entries)
+	return string(b)
 }
 
 func (g *Graph) SPrintTree() string {
@@ -499,49 +893,34 @@ func (g *Graph) sPrintTree(path string, o *Object, buf *bytes.Buffer) error {
 	} else {
 		value = fmt.Sprintf("%v", o.Value)
 	}
-	show := fmt.Sprintf("%s%s(%v=%v)\n", path, o.Name, o.reflectType, value)
-	buf.WriteString(show)
+	show := ""
+	if o.template {
+		show = fmt.Sprintf("%s%s(%v) [%s]", path, o.Name, o.reflectType, ScopePrototype)
+	} else if o.prototype {
+		show = fmt.Sprintf("%s%s(%v=%v) [%s]", path, o.Name, o.reflectType, value, ScopePrototype)
+	} else {
+		show = fmt.Sprintf("%s%s(%v=%v)", path, o.Name, o.reflectType, value)
+	}
+	if len(o.decorators) > 0 {
+		show += fmt.Sprintf(" [decorators=%s]", strings.Join(o.decorators, " -> "))
+	}
+	buf.WriteString(show + "\n")
 
-	if isStructPtr(o.reflectType) {
+	if len(o.deps) > 0 {
 		childPath := path
 		childPath = strings.Replace(childPath, "└", " ", -1)
 		childPath = strings.Replace(childPath, "├", "│", -1)
 		childPath = strings.Replace(childPath, "─", " ", -1)
 		childPath = strings.Replace(childPath, "┌", "│", -1)
 
-		t := o.reflectType.Elem()
-
-		// load tags of injected child
-		var tags []string
-		for i := 0; i < t.NumField(); i++ {
-			structFiled := t.Field(i)
-			ok, tag, err := structtag.Extract("inject", string(structFiled.Tag))
-			if err != nil {
-				return fmt.Errorf("extract tag fail,f=%s,err=%v", structFiled.Name, err)
-			}
-			if !ok {
-				continue
-			}
-
-			if len(tag) == 0 {
-				tag = getTypeName(structFiled.Type)
-			}
-
-			_, ok = g.find(tag)
-			if ok {
-				tags = append(tags, tag)
-			}
-		}
-
-		for i, tag := range tags {
+		for i, edge := range o.deps {
 			corner := ""
-			if i == len(tags)-1 {
+			if i == len(o.deps)-1 {
 				corner = childPath + " └── "
 			} else {
 				corner = childPath + " ├── "
 			}
-			childObject, _ := g.find(tag)
-			g.sPrintTree(corner, childObject, buf)
+			g.sPrintTree(corner, edge.To, buf)
 		}
 	}
 
@@ -553,7 +932,16 @@ func (g *Graph) sPrintTree(path string, o *Object, buf *bytes.Buffer) error {
 //of the Register
 //there should be no defer xx.Close betwen g.Register
 //function calls in main.exe
-func (g *Graph) Close() {
+//a failed or panicking Close does not stop closing the others,
+//all the failures are joined into the returned error
+func (g *Graph) Close() error {
+	return g.CloseContext(context.Background())
+}
+
+//CloseContext close objects like Close,
+//when ctx is done the remaining objects are not closed,
+//objects not closed are kept in graph and returned in the error
+func (g *Graph) CloseContext(ctx context.Context) error {
 	g.l.Lock()
 	defer g.l.Unlock()
 
@@ -561,42 +949,131 @@ func (g *Graph) Close() {
 		g.Logger.Info("close objects %v", g.sPrint())
 	}
 	var keys []string
+	var errs []error
+	failed := make(map[*Object]bool)
 	iter := g.named.RevIterFunc()
 	for kv, ok := iter(); ok; kv, ok = iter() {
 		k, ok := kv.Key.(string)
 		if !ok {
 			continue
 		}
-		keys = append(keys, k)
 		o, ok := kv.Value.(*Object)
-		if !ok {
-			continue
-		}
-		if o.closed {
-			continue
-		}
-		if isStructPtr(o.reflectType) {
-			keys = append(keys, getTypeName(o.reflectType))
-		}
-		if o.Value == nil {
+		if ok && failed[o] {
 			continue
 		}
-		c, ok := o.Value.(Closeable)
-		if ok {
-			c.Close()
-			if g.Logger != nil {
-				g.Logger.Debug("closed!object=%s", o)
+		if ok && !o.template {
+			err := g.closeObject(ctx, o)
+			if err != nil {
+				err = fmt.Errorf("close object fail,name=%s,err=%w", o.Name, err)
+				if g.Logger != nil {
+					g.Logger.Error(err)
+				}
+				failed[o] = true
+				errs = append(errs, err)
+				continue
 			}
-			o.closed = true
 		}
+		keys = append(keys, k)
 	}
 
+	removed := make(map[*Object]bool)
 	for _, k := range keys {
+		o, ok := g.findLocal(k)
+		if ok && failed[o] {
+			continue
+		}
+		if ok && !removed[o] {
+			removed[o] = true
+			g.remove(o)
+		}
 		g.del(k)
 	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
 	if g.Logger != nil {
 		g.Logger.Info("inject graph closed all")
 	}
+	return nil
+}
+
+//closeObject close o, then the prototypes created for it
+//on reverse order of their creation
+func (g *Graph) closeObject(ctx context.Context, o *Object) error {
+	var errs []error
+	if !o.closed && !o.deferred {
+		err := g.closeOne(ctx, o)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for i := len(o.instances) - 1; i >= 0; i-- {
+		err := g.closeObject(ctx, o.instances[i])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("prototype %s:%w", o.instances[i].Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (g *Graph) closeOne(ctx context.Context, o *Object) error {
+	err := ctx.Err()
+	if err != nil {
+		return err
+	}
+
+	var run func(ctx context.Context) error
+	switch c := o.Value.(type) {
+	case CloseableContext:
+		run = c.Close
+	case CloseableWithError:
+		run = func(context.Context) error {
+			return c.Close()
+		}
+	case Closeable:
+		run = func(context.Context) error {
+			c.Close()
+			return nil
+		}
+	}
+	if run != nil {
+		timeout := o.closeTimeout
+		if timeout <= 0 {
+			timeout = g.DefaultCloseTimeout
+		}
+		cctx := ctx
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			cctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		g.emit(Event{Kind: EventBeforeClose, Object: o})
+		st := time.Now()
+		err = runContext(cctx, recoverClose(run))
+		o.closeTime = time.Since(st)
+		g.emit(Event{Kind: EventAfterClose, Object: o, Duration: o.closeTime, Err: err})
+		if err != nil {
+			return err
+		}
+		if g.Logger != nil {
+			g.Logger.Debug("closed!object=%s", o)
+		}
+	}
+	o.closed = true
+	return nil
+}
+
+//recoverClose turn a panic of close into an error
+func recoverClose(run func(ctx context.Context) error) func(ctx context.Context) error {
+	return func(ctx context.Context) (err error) {
+		defer func() {
+			if x := recover(); x != nil {
+				err = fmt.Errorf("close panic:%v", x)
+			}
+		}()
+		return run(ctx)
+	}
 }
 
 func isStructPtr(t reflect.Type) bool {
@@ -1,10 +1,12 @@
 package inji
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"reflect"
 	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/teou/implmap"
@@ -427,27 +429,61 @@ type Rec2 struct {
 	Rec1 *Rec1 `inject:"rec1"`
 }
 
-//causing a stack overflow error
-func _TestRec(t *testing.T) {
+func TestRec(t *testing.T) {
 	fmt.Println("############## test rec")
 	InitDefault()
 	l := &Log{}
 	SetLogger(l)
 	defer Close()
 
-	defer func() {
-		if x := recover(); x != nil {
-			fmt.Println("panic : ", x)
-		}
-	}()
 	haha := "aa"
 	RegisterOrFail("haha", haha)
 
-	//this will cause:
-	//runtime: goroutine stack exceeds 1000000000-byte limit
-	//fatal error: stack overflow
-	RegisterOrFail("rec1", (*Rec1)(nil))
-	RegisterOrFail("rec2", (*Rec2)(nil))
+	_, err := Register("rec1", (*Rec1)(nil))
+	var cycle *CycleError
+	if !errors.As(err, &cycle) {
+		t.Error("rec1 should fail with cycle error", err)
+		return
+	}
+	fmt.Println(err)
+	if len(cycle.Path) != 3 || cycle.Path[0].Name != "rec1" || cycle.Path[1].Name != "rec2" || cycle.Path[2].Name != "rec1" {
+		t.Error("invalid cycle path", cycle.Path)
+		return
+	}
+	if cycle.Path[0].Field != "Rec2" || cycle.Path[1].Field != "Rec1" {
+		t.Error("invalid cycle fields", cycle.Path)
+		return
+	}
+	if !strings.Contains(err.Error(), "rec1(*inji.Rec1).Rec2 -> rec2(*inji.Rec2).Rec1 -> rec1(*inji.Rec1)") {
+		t.Error("invalid cycle message", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	fmt.Println("############## test validate")
+	InitDefault()
+	defer Close()
+
+	RegisterOrFail("haha", "aa")
+	err := _g.Validate(Registration{Name: "rec1", Value: (*Rec1)(nil)})
+	var cycle *CycleError
+	if !errors.As(err, &cycle) {
+		t.Error("rec1 should not be valid", err)
+		return
+	}
+	fmt.Println(err)
+	if GraphLen() != 1 {
+		t.Error("validate should not register anything", GraphPrint())
+		return
+	}
+
+	err = _g.Validate(
+		Registration{Name: "conf", Value: "##conf1"},
+		Registration{Name: "test2", Value: (*Test2)(nil)},
+	)
+	if err != nil {
+		t.Error("test2 should be valid", err)
+	}
 }
 
 type Dep struct {
@@ -688,3 +724,92 @@ func compareTree(str1 string, str2 string) bool {
 	str2 = re.ReplaceAllString(str2, "")
 	return str1 == str2
 }
+
+type Proto struct {
+	Conf    string `inject:"conf"`
+	started int
+	closed  int
+}
+
+func (p *Proto) Start() error {
+	p.started++
+	return nil
+}
+
+func (p *Proto) Close() {
+	p.closed++
+}
+
+type ProtoUser struct {
+	Ptr   *Proto `inject:"proto" scope:"prototype"`
+	Value Proto  `inject:"protoValue"`
+}
+
+func TestPrototype(t *testing.T) {
+	fmt.Println("############## test prototype")
+	InitDefault()
+	l := &Log{}
+	SetLogger(l)
+
+	RegisterOrFail("conf", "##conf1")
+	RegisterOrFail("protoValue", Proto{Conf: "from template"})
+	u1 := RegisterOrFail("u1", (*ProtoUser)(nil)).(*ProtoUser)
+	u2 := RegisterOrFail("u2", (*ProtoUser)(nil)).(*ProtoUser)
+
+	if u1.Ptr == nil || u2.Ptr == nil || u1.Ptr == u2.Ptr {
+		t.Error("prototype ptr should be created on every inject", u1.Ptr, u2.Ptr)
+		return
+	}
+	if u1.Ptr.Conf != "##conf1" || u1.Ptr.started != 1 {
+		t.Error("prototype ptr should be injected and started", u1.Ptr)
+		return
+	}
+	if u1.Value.Conf != "from template" || u1.Value.started != 1 || u2.Value.started != 1 {
+		t.Error("prototype value should be copied from template and started", u1.Value, u2.Value)
+		return
+	}
+	if _, ok := Find("proto"); ok {
+		t.Error("prototype should not be set into graph")
+		return
+	}
+	if GraphLen() != 4 {
+		t.Error("graph should only contain conf,protoValue,u1,u2", GraphPrint())
+		return
+	}
+
+	tree := GraphPrintTree()
+	fmt.Println(tree)
+	if !strings.Contains(tree, "proto(*inji.Proto=") || !strings.Contains(tree, "protoValue(inji.Proto) [prototype]") {
+		t.Error("tree should show prototypes", tree)
+		return
+	}
+
+	Close()
+	if u1.Ptr.closed != 1 || u2.Ptr.closed != 1 || u1.Value.closed != 1 || u2.Value.closed != 1 {
+		t.Error("prototypes should be closed with graph", u1, u2)
+	}
+}
+
+type PlainProtoUser struct {
+	Value Proto `inject:""`
+}
+
+func TestPrototypeNoTemplate(t *testing.T) {
+	fmt.Println("############## test prototype no template")
+	g := NewTestGraph(t)
+	g.RegisterOrFail("conf", "##conf2")
+
+	u1 := g.RegisterOrFail("u1", (*PlainProtoUser)(nil)).(*PlainProtoUser)
+	u2 := g.RegisterOrFail("u2", (*PlainProtoUser)(nil)).(*PlainProtoUser)
+	if u1.Value.Conf != "##conf2" || u1.Value.started != 1 || u2.Value.started != 1 {
+		t.Error("struct fields should be prototypes without a template", u1, u2)
+		return
+	}
+	if _, ok := g.FindByType(reflect.TypeOf(Proto{})); ok {
+		t.Error("prototype should not be set into graph")
+		return
+	}
+	if g.Len() != 3 {
+		t.Error("graph should only contain conf,u1,u2", g.SPrint())
+	}
+}
@@ -1,5 +1,9 @@
 package inji
 
+import (
+	"context"
+)
+
 type Startable interface {
 	Start() error
 }
@@ -8,7 +12,33 @@ type Closeable interface {
 	Close()
 }
 
+//CloseableWithError is preferred to Closeable,
+//the returned error is reported by graph.Close
+type CloseableWithError interface {
+	Close() error
+}
+
 type Injectable interface {
 	Startable
 	Closeable
 }
+
+//StartableContext is preferred to Startable,
+//ctx is done when the start timeout is reached,
+//a Start(of both) not returning by then keeps running in background
+//and its result is dropped, so it should return once ctx is done
+type StartableContext interface {
+	Start(ctx context.Context) error
+}
+
+//CloseableContext is preferred to Closeable,
+//ctx is done when the close timeout is reached
+type CloseableContext interface {
+	Close(ctx context.Context) error
+}
+
+//Reloadable is notified after an object it depends on
+//is changed by Graph.Update
+type Reloadable interface {
+	OnChange(old, new *Object)
+}
@@ -1,6 +1,7 @@
 package inji
 
 import (
+	"context"
 	"reflect"
 )
 
@@ -10,8 +11,16 @@ func InitDefault() {
 	_g = NewGraph()
 }
 
-func Close() {
-	_g.Close()
+func NewChild() *Graph {
+	return _g.NewChild()
+}
+
+func Close() error {
+	return _g.Close()
+}
+
+func CloseContext(ctx context.Context) error {
+	return _g.CloseContext(ctx)
 }
 
 func SetLogger(logger Logger) {
@@ -38,6 +47,18 @@ func Register(name string, value interface{}) (interface{}, error) {
 	return _g.Register(name, value)
 }
 
+func RegisterWith(name string, value interface{}, opts ...Option) (interface{}, error) {
+	return _g.RegisterWith(name, value, opts...)
+}
+
+func RegisterGroup(group string, value interface{}, opts ...Option) (interface{}, error) {
+	return _g.RegisterGroup(group, value, opts...)
+}
+
+func RegisterOrFailGroup(group string, value interface{}, opts ...Option) interface{} {
+	return _g.RegisterOrFailGroup(group, value, opts...)
+}
+
 func RegisterOrFailSingleNoFill(name string, value interface{}) interface{} {
 	return _g.RegisterOrFailSingleNoFill(name, value)
 }
@@ -50,6 +71,52 @@ func RegisterSingle(name string, value interface{}) (interface{}, error) {
 	return _g.RegisterSingle(name, value)
 }
 
+//ProvideWith is g.Provide of the default graph,
+//Provide is taken by the typed Provide[T]
+func ProvideWith(name string, fn interface{}, opts ...Option) (interface{}, error) {
+	return _g.Provide(name, fn, opts...)
+}
+
+func ProvideOrFail(name string, fn interface{}, opts ...Option) interface{} {
+	return _g.ProvideOrFail(name, fn, opts...)
+}
+
+func Bind(iface reflect.Type, impl interface{}) error {
+	return _g.Bind(iface, impl)
+}
+
+func LoadConfig(sources ...ConfigSource) error {
+	return _g.LoadConfig(sources...)
+}
+
+func Config(key string) (interface{}, bool) {
+	return _g.Config(key)
+}
+
+func RegisterConverter(from reflect.Type, to reflect.Type, fn ConvertFunc) error {
+	return _g.RegisterConverter(from, to, fn)
+}
+
+func Update(name string, value interface{}) error {
+	return _g.Update(name, value)
+}
+
+func Decorate(target interface{}, fn interface{}) error {
+	return _g.Decorate(target, fn)
+}
+
+func Override(name string, value interface{}) error {
+	return _g.Override(name, value)
+}
+
+func Replace(name string, value interface{}) error {
+	return _g.Replace(name, value)
+}
+
+func Unregister(name string, mode UnregisterMode) error {
+	return _g.Unregister(name, mode)
+}
+
 func FindByType(t reflect.Type) (interface{}, bool) {
 	o, ok := _g.FindByType(t)
 	if !ok || o == nil || o.Value == nil {
@@ -78,3 +145,14 @@ func GraphPrintTree() string {
 	return _g.SPrintTree()
 }
 
+func GraphDOT() string {
+	return _g.DOT()
+}
+
+func GraphMermaid() string {
+	return _g.Mermaid()
+}
+
+func GraphJSON() ([]byte, error) {
+	return _g.JSON()
+}
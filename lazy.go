@@ -0,0 +1,228 @@
+package inji
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/facebookgo/structtag"
+	"github.com/teou/ordered_map"
+)
+
+//Lazy is a dependency created and started on the first Get,
+//a field of type inji.Lazy[T] or *inji.Lazy[T] tagged `inject:"name"`
+//is resolved like a field of type T with the same tags, but only
+//when Get is called, objects created by Get are closed after
+//the object owning the field
+type Lazy[T any] struct {
+	s *lazyState
+}
+
+type lazyState struct {
+	l       sync.Mutex
+	resolve func() (interface{}, error)
+	//prototype is resolved on every get, never cached
+	prototype bool
+	done      bool
+	v         interface{}
+}
+
+//get resolve the dependency once, or every time for a prototype
+func (s *lazyState) get() (interface{}, error) {
+	if s.prototype {
+		return s.resolve()
+	}
+	s.l.Lock()
+	defer s.l.Unlock()
+	if !s.done {
+		v, err := s.resolve()
+		if err != nil {
+			return nil, err
+		}
+		s.v = v
+		s.done = true
+	}
+	return s.v, nil
+}
+
+//Get resolve the dependency once, a failed resolving is retried
+//by the next Get, Get locks the graph, so in Start it can only be called
+//by the owner of the field, whose Start resolves it by its Resolver
+func (l *Lazy[T]) Get() (T, error) {
+	var zero T
+	if l.s == nil {
+		return zero, fmt.Errorf("lazy %v is not injected", typeOf[T]())
+	}
+	v, err := l.s.get()
+	if err != nil {
+		return zero, err
+	}
+	t, _ := v.(T)
+	return t, nil
+}
+
+func (l *Lazy[T]) elemType() reflect.Type {
+	return typeOf[T]()
+}
+
+func (l *Lazy[T]) isProvider() bool {
+	return false
+}
+
+func (l *Lazy[T]) setState(s *lazyState) {
+	l.s = s
+}
+
+//Provider resolve its dependency on Get,
+//a field of type inji.Provider[T] or *inji.Provider[T] tagged
+//`inject:"name" scope:"prototype"` gets a new instance from each Get,
+//which is started but owned by the caller, the graph neither tracks
+//nor closes it, so the caller closes it when done,
+//otherwise the object of graph is returned, created on the first Get
+//and kept like Lazy does
+type Provider[T any] struct {
+	s *lazyState
+}
+
+//Get resolve the dependency, it can be called in Start like Lazy.Get
+func (p *Provider[T]) Get() (T, error) {
+	var zero T
+	if p.s == nil {
+		return zero, fmt.Errorf("provider %v is not injected", typeOf[T]())
+	}
+	v, err := p.s.get()
+	if err != nil {
+		return zero, err
+	}
+	t, _ := v.(T)
+	return t, nil
+}
+
+func (p *Provider[T]) elemType() reflect.Type {
+	return typeOf[T]()
+}
+
+func (p *Provider[T]) isProvider() bool {
+	return true
+}
+
+func (p *Provider[T]) setState(s *lazyState) {
+	p.s = s
+}
+
+//lazyField is implemented by *Lazy[T] and *Provider[T]
+type lazyField interface {
+	elemType() reflect.Type
+	//isProvider tell if a prototype field creates an instance on each Get
+	isProvider() bool
+	setState(s *lazyState)
+}
+
+var lazyFieldType = reflect.TypeOf((*lazyField)(nil)).Elem()
+
+//isLazyField tell if a field of type t is a Lazy or a Provider
+func isLazyField(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		return t.Implements(lazyFieldType)
+	}
+	return reflect.PtrTo(t).Implements(lazyFieldType)
+}
+
+//injectLazy set the Lazy or Provider field f of o
+func (g *Graph) injectLazy(o *Object, f reflect.StructField, vf reflect.Value) {
+	var lf lazyField
+	if f.Type.Kind() == reflect.Ptr {
+		vf.Set(reflect.New(f.Type.Elem()))
+		lf = vf.Interface().(lazyField)
+	} else {
+		lf = vf.Addr().Interface().(lazyField)
+	}
+	_, scopeStr, _ := structtag.Extract("scope", string(f.Tag))
+	prototype := lf.isProvider() && scopeStr == ScopePrototype
+	lf.setState(&lazyState{resolve: g.lazyResolve(o, f, lf.elemType(), prototype), prototype: prototype})
+}
+
+//lazyResolve return a func resolving field f of o as a field of type t,
+//the instance created for an owned prototype field is not tracked by o
+func (g *Graph) lazyResolve(o *Object, f reflect.StructField, t reflect.Type, owned bool) func() (interface{}, error) {
+	holderType := reflect.StructOf([]reflect.StructField{{Name: f.Name, Type: t, Tag: f.Tag}})
+	resolve := func() (interface{}, error) {
+		frame, err := g.enter(o.Name, o.reflectType)
+		if err != nil {
+			return nil, err
+		}
+		defer g.leave()
+
+		deps, instances := len(o.deps), len(o.instances)
+		holder := reflect.New(holderType)
+		err = g.fill(o, frame, holder, false)
+		if err != nil {
+			o.deps, o.instances = o.deps[:deps], o.instances[:instances]
+			return nil, err
+		}
+		if owned {
+			//the instance is owned by the caller
+			o.deps, o.instances = o.deps[:deps], o.instances[:instances]
+		}
+		for _, e := range o.deps[deps:] {
+			if !hasEdge(o.deps[:deps], e) {
+				o.deps = append(o.deps[:deps], e)
+				deps++
+			}
+		}
+		o.deps = o.deps[:deps]
+		g.moveBefore(g.tx.objects, o)
+		return holder.Elem().Field(0).Interface(), nil
+	}
+	return func() (interface{}, error) {
+		//the graph is locked by the registration starting o,
+		//go through the resolver of the start
+		if r, ok := g.startingResolver(o); ok && r.enter() == nil {
+			defer r.leave()
+			return g.nestedTransact(resolve)
+		}
+		g.l.Lock()
+		defer g.l.Unlock()
+		if o.closed {
+			return nil, fmt.Errorf("object is closed,name=%s,field=%s", o.Name, f.Name)
+		}
+		return g.transact(resolve)
+	}
+}
+
+func hasEdge(edges []*Edge, e *Edge) bool {
+	for _, old := range edges {
+		if old.Field == e.Field && old.To == e.To {
+			return true
+		}
+	}
+	return false
+}
+
+//moveBefore move the keys of objs before the first key of o,
+//objs created for o after o itself are closed after o
+func (g *Graph) moveBefore(objs []*Object, o *Object) {
+	moving := make(map[*Object]bool)
+	for _, obj := range objs {
+		moving[obj] = true
+	}
+	var kept, moved []*ordered_map.KVPair
+	at := -1
+	iter := g.named.IterFunc()
+	for kv, ok := iter(); ok; kv, ok = iter() {
+		obj, _ := kv.Value.(*Object)
+		if moving[obj] {
+			moved = append(moved, kv)
+			continue
+		}
+		if obj == o && at < 0 {
+			at = len(kept)
+		}
+		kept = append(kept, kv)
+	}
+	if at < 0 || len(moved) == 0 {
+		return
+	}
+	pairs := append(append(append([]*ordered_map.KVPair{}, kept[:at]...), moved...), kept[at:]...)
+	g.named = ordered_map.NewOrderedMapWithArgs(pairs)
+}
@@ -0,0 +1,228 @@
+package inji
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+var lazyEvents []string
+
+type LazyClient struct {
+	Conf string `inject:"conf"`
+}
+
+func (c *LazyClient) Start() error {
+	lazyEvents = append(lazyEvents, "start client")
+	return nil
+}
+
+func (c *LazyClient) Close() {
+	lazyEvents = append(lazyEvents, "close client")
+}
+
+type LazyConn struct {
+	ID int
+}
+
+func (c *LazyConn) Close() {
+	lazyEvents = append(lazyEvents, "close conn")
+}
+
+type LazyUser struct {
+	Client Lazy[*LazyClient]    `inject:""`
+	Conn   *Provider[*LazyConn] `inject:"" scope:"prototype"`
+	Fail   Lazy[*FailClient]    `inject:""`
+	Unused Lazy[*ClientA]       `inject:""`
+	Self   *Provider[*LazyUser] `inject:"lazy_user"`
+	None   Lazy[*LazyMissing]   `inject:"none" cannil:"true"`
+}
+
+type LazyMissing struct {
+	V string `inject:"not_registered"`
+}
+
+func (u *LazyUser) Close() {
+	lazyEvents = append(lazyEvents, "close user")
+}
+
+func TestLazy(t *testing.T) {
+	fmt.Println("############## test lazy")
+	lazyEvents = nil
+	g := NewGraph()
+
+	g.RegisterOrFail("conf", "##conf1")
+	u := g.RegisterOrFail("lazy_user", (*LazyUser)(nil)).(*LazyUser)
+	if _, ok := g.FindByType(typeOf[*LazyClient]()); ok || len(lazyEvents) != 0 {
+		t.Error("lazy client should not be created", lazyEvents)
+		return
+	}
+
+	var wg sync.WaitGroup
+	clients := make([]*LazyClient, 4)
+	for i := range clients {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			clients[i], _ = u.Client.Get()
+		}(i)
+	}
+	wg.Wait()
+	for _, c := range clients {
+		if c == nil || c != clients[0] || c.Conf != "##conf1" {
+			t.Error("lazy client should be created once", clients)
+			return
+		}
+	}
+	if found, ok := g.FindByType(typeOf[*LazyClient]()); !ok || found.Value != clients[0] {
+		t.Error("lazy client should be in graph")
+		return
+	}
+
+	c1, err := u.Conn.Get()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	c2, _ := u.Conn.Get()
+	if c1 == c2 || c1 == nil {
+		t.Error("provider of prototype should create new instances")
+		return
+	}
+	//instances of a prototype provider are owned by the caller
+	c1.Close()
+	c2.Close()
+	for i := 0; i < 100; i++ {
+		u.Conn.Get()
+	}
+	owner, _ := g.Find("lazy_user")
+	if len(owner.instances) != 0 || len(owner.deps) != 1 {
+		t.Error("instances of a prototype provider should not be tracked", len(owner.instances), owner.deps)
+		return
+	}
+	self, err := u.Self.Get()
+	if err != nil || self != u {
+		t.Error("provider should find object of graph", err)
+		return
+	}
+	//the object of graph is kept after the first Get
+	done := make(chan *LazyUser, 1)
+	g.l.Lock()
+	go func() {
+		self, _ := u.Self.Get()
+		done <- self
+	}()
+	select {
+	case self = <-done:
+	case <-time.After(5 * time.Second):
+	}
+	g.l.Unlock()
+	if self != u {
+		t.Error("provider should keep the object of graph", self)
+		return
+	}
+
+	_, err = u.Fail.Get()
+	if err == nil {
+		t.Error("start of FailClient should fail")
+		return
+	}
+	fmt.Println(err)
+	if _, ok := g.FindByType(typeOf[*FailClient]()); ok {
+		t.Error("failed lazy should be rolled back")
+		return
+	}
+	none, err := u.None.Get()
+	if err != nil || none != nil {
+		t.Error("lazy with cannil should be nil", none, err)
+		return
+	}
+	if _, ok := g.FindByType(typeOf[*ClientA]()); ok {
+		t.Error("unused lazy should not be created")
+		return
+	}
+
+	g.Close()
+	want := "[start client close conn close conn close user close client]"
+	if fmt.Sprint(lazyEvents) != want {
+		t.Error("lazy objects should be closed after their owner", lazyEvents)
+	}
+}
+
+type LazyA struct {
+	B Lazy[*LazyB] `inject:""`
+}
+
+type LazyB struct {
+	A *LazyA `inject:""`
+}
+
+func TestLazyCycle(t *testing.T) {
+	fmt.Println("############## test lazy cycle")
+	g := NewGraph()
+	defer g.Close()
+
+	a := g.RegisterOrFail("", (*LazyA)(nil)).(*LazyA)
+	b, err := a.B.Get()
+	if err != nil || b.A != a {
+		t.Error("lazy should break dependency cycle", err)
+	}
+}
+
+type LazyStarter struct {
+	Client Lazy[*LazyClient]    `inject:""`
+	Conn   *Provider[*LazyConn] `inject:"" scope:"prototype"`
+	client *LazyClient
+}
+
+func (s *LazyStarter) Start() error {
+	var err error
+	s.client, err = s.Client.Get()
+	if err != nil {
+		return err
+	}
+	_, err = s.Conn.Get()
+	return err
+}
+
+func TestLazyInStart(t *testing.T) {
+	fmt.Println("############## test lazy in start")
+	for _, workers := range []int{0, 2} {
+		g := NewTestGraph(t)
+		g.StartWorkers = workers
+		g.RegisterOrFail("conf", "##conf")
+
+		done := make(chan error, 1)
+		go func() {
+			_, err := g.Register("starter", (*LazyStarter)(nil))
+			done <- err
+		}()
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Error(err)
+				return
+			}
+		case <-time.After(5 * time.Second):
+			t.Error("Get in Start should not deadlock")
+			return
+		}
+		s, _ := g.Find("starter")
+		starter := s.Value.(*LazyStarter)
+		if starter.client == nil || starter.client.Conf != "##conf" {
+			t.Error("lazy client should be resolved in Start", starter.client)
+			return
+		}
+		//resolved by the resolver, then reused
+		c, err := starter.Client.Get()
+		if err != nil || c != starter.client {
+			t.Error("lazy client should be resolved once", c, err)
+			return
+		}
+		if _, ok := g.FindByType(reflect.TypeOf(&LazyClient{})); !ok {
+			t.Error("lazy client should be registered", g.SPrint())
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package inji
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+type SlowStart struct {
+	Wait    time.Duration
+	started bool
+}
+
+func (s *SlowStart) Start(ctx context.Context) error {
+	select {
+	case <-time.After(s.Wait):
+		s.started = true
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type SlowStartUser struct {
+	Slow *SlowStart `inject:"slow" starttimeout:"10ms"`
+}
+
+type SlowClose struct {
+	Wait   time.Duration
+	closed bool
+}
+
+func (s *SlowClose) Close(ctx context.Context) error {
+	select {
+	case <-time.After(s.Wait):
+		s.closed = true
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestStartTimeout(t *testing.T) {
+	fmt.Println("############## test start timeout")
+	g := NewGraph()
+	defer g.Close()
+
+	s, err := g.RegisterWith("fast", &SlowStart{Wait: time.Millisecond}, StartTimeout(time.Second))
+	if err != nil || !s.(*SlowStart).started {
+		t.Error("fast should be started", err)
+		return
+	}
+
+	_, err = g.RegisterWith("slow", &SlowStart{Wait: time.Second}, StartTimeout(10*time.Millisecond))
+	if err == nil || !strings.Contains(err.Error(), "deadline exceeded") {
+		t.Error("slow start should time out", err)
+		return
+	}
+	fmt.Println(err)
+
+	//tag of the field bound the auto created slow
+	_, err = g.Register("user", (*SlowStartUser)(nil))
+	if err != nil {
+		t.Error("zero wait should not time out", err)
+		return
+	}
+
+	g.DefaultStartTimeout = 10 * time.Millisecond
+	_, err = g.Register("slow", &SlowStart{Wait: time.Second})
+	if err == nil {
+		t.Error("slow start should time out by graph default")
+	}
+}
+
+type PanicStart struct {
+}
+
+func (p *PanicStart) Start() error {
+	panic("start panic")
+}
+
+func TestStartTimeoutPanic(t *testing.T) {
+	fmt.Println("############## test start timeout panic")
+	g := NewTestGraph(t)
+	g.DefaultStartTimeout = time.Second
+
+	_, err := g.Register("panic", &PanicStart{})
+	var se *StartError
+	if !errors.As(err, &se) || !strings.Contains(err.Error(), "start panic") {
+		t.Error("panic of a bounded start should be returned", err)
+		return
+	}
+	if _, ok := g.Find("panic"); ok {
+		t.Error("panic should not be registered")
+	}
+}
+
+func TestCloseContext(t *testing.T) {
+	fmt.Println("############## test close context")
+	g := NewGraph()
+
+	g.RegisterOrFail("first", &Sin1{Name: "first"})
+	g.RegisterOrFail("slow", &SlowClose{Wait: 200 * time.Millisecond})
+	fast := g.RegisterOrFail("fast", &SlowClose{Wait: time.Millisecond}).(*SlowClose)
+	_, err := g.RegisterWith("bounded", &SlowClose{Wait: time.Second}, CloseTimeout(10*time.Millisecond))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = g.CloseContext(ctx)
+	if err == nil {
+		t.Error("slow close should fail")
+		return
+	}
+	fmt.Println(err)
+	msg := err.Error()
+	if !strings.Contains(msg, "name=bounded") || !strings.Contains(msg, "name=slow") || !strings.Contains(msg, "name=first") {
+		t.Error("objects not closed should be reported", msg)
+		return
+	}
+	if !fast.closed {
+		t.Error("fast should be closed before the deadline")
+		return
+	}
+	if _, ok := g.Find("fast"); ok {
+		t.Error("closed fast should be removed from graph")
+		return
+	}
+	if _, ok := g.Find("first"); !ok {
+		t.Error("first is not closed and should be kept in graph")
+		return
+	}
+
+	err = g.CloseContext(context.Background())
+	if !strings.Contains(fmt.Sprint(err), "name=bounded") || strings.Contains(fmt.Sprint(err), "name=first") {
+		t.Error("only bounded should fail on second close", err)
+	}
+}
+
+var errCloseDB = errors.New("db close fail")
+
+type ErrCloser struct{}
+
+func (c *ErrCloser) Close() error {
+	return errCloseDB
+}
+
+type PanicCloser struct{}
+
+func (c *PanicCloser) Close() {
+	panic("close panic!")
+}
+
+func TestCloseErrors(t *testing.T) {
+	fmt.Println("############## test close errors")
+	g := NewGraph()
+	g.Logger = &Log{}
+
+	first := g.RegisterOrFail("first", &SlowClose{}).(*SlowClose)
+	g.RegisterOrFail("db", &ErrCloser{})
+	g.RegisterOrFail("panic", &PanicCloser{})
+	last := g.RegisterOrFail("last", &SlowClose{}).(*SlowClose)
+
+	err := g.Close()
+	if err == nil {
+		t.Error("close should fail")
+		return
+	}
+	fmt.Println(err)
+	if !errors.Is(err, errCloseDB) {
+		t.Error("db close error should be joined", err)
+		return
+	}
+	msg := err.Error()
+	if !strings.Contains(msg, "name=db") || !strings.Contains(msg, "name=panic") || !strings.Contains(msg, "close panic!") {
+		t.Error("every failed object should be named", msg)
+		return
+	}
+	if !first.closed || !last.closed {
+		t.Error("failures should not stop closing the others")
+	}
+}
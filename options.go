@@ -0,0 +1,73 @@
+package inji
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/facebookgo/structtag"
+)
+
+//options of one registered object
+type options struct {
+	startTimeout time.Duration
+	closeTimeout time.Duration
+	groups       []string
+	implements   []reflect.Type
+}
+
+type Option func(*options)
+
+//StartTimeout bound the Start of the object,
+//it overrides graph.DefaultStartTimeout
+func StartTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.startTimeout = d
+	}
+}
+
+//CloseTimeout bound the Close of the object,
+//it overrides graph.DefaultCloseTimeout
+func CloseTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.closeTimeout = d
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+func (opts options) apply(o *Object) {
+	o.startTimeout = opts.startTimeout
+	o.closeTimeout = opts.closeTimeout
+	o.groups = opts.groups
+	o.implements = opts.implements
+}
+
+//tagOptions read the options of the object created for field f
+//	`starttimeout:"3s" closetimeout:"1s"`
+func tagOptions(f reflect.StructField) (options, error) {
+	o := options{}
+	_, startStr, _ := structtag.Extract("starttimeout", string(f.Tag))
+	if startStr != "" {
+		d, err := time.ParseDuration(startStr)
+		if err != nil {
+			return o, fmt.Errorf("%w,invalid starttimeout,field=%s,err=%v", ErrInvalidRegistration, f.Name, err)
+		}
+		o.startTimeout = d
+	}
+	_, closeStr, _ := structtag.Extract("closetimeout", string(f.Tag))
+	if closeStr != "" {
+		d, err := time.ParseDuration(closeStr)
+		if err != nil {
+			return o, fmt.Errorf("%w,invalid closetimeout,field=%s,err=%v", ErrInvalidRegistration, f.Name, err)
+		}
+		o.closeTimeout = d
+	}
+	return o, nil
+}
@@ -0,0 +1,142 @@
+package inji
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+//StartSpan is the start of one Startable object,
+//Worker is the goroutine of StartWorkers it was started on,
+//0 means it was started by the registering goroutine
+type StartSpan struct {
+	Name   string
+	Worker int
+	Begin  time.Time
+	End    time.Time
+	Err    error
+}
+
+func (s StartSpan) Duration() time.Duration {
+	return s.End.Sub(s.Begin)
+}
+
+//StartTimeline return the starts of objects on the order they finished
+func (g *Graph) StartTimeline() []StartSpan {
+	g.timelineL.Lock()
+	defer g.timelineL.Unlock()
+	return append([]StartSpan{}, g.timeline...)
+}
+
+func (g *Graph) record(s StartSpan) {
+	g.timelineL.Lock()
+	g.timeline = append(g.timeline, s)
+	g.timelineL.Unlock()
+}
+
+//startOrDefer start o now, or defer it to the end of
+//the registration when StartWorkers is set
+func (g *Graph) startOrDefer(o *Object) error {
+	if g.StartWorkers > 0 && g.tx != nil && !g.tx.starting {
+		o.deferred = true
+		g.tx.pending = append(g.tx.pending, o)
+		return nil
+	}
+	return g.start(o, 0)
+}
+
+//startRecover start o on worker w, a panic of Start is returned
+//as the start error of o, the registration is rolled back by it
+func (g *Graph) startRecover(o *Object, w int) (err error) {
+	defer func() {
+		if x := recover(); x != nil {
+			err = &StartError{Name: o.Name, Type: o.reflectType, Err: fmt.Errorf("panic:%v", x)}
+		}
+	}()
+	return g.start(o, w)
+}
+
+type startResult struct {
+	i       int
+	err     error
+	skipped bool
+}
+
+//startAll start pending objects on at most StartWorkers goroutines,
+//an object is started after the pending objects injected into it,
+//nothing new is started once a start fails
+func (g *Graph) startAll(pending []*Object) error {
+	index := make(map[*Object]int, len(pending))
+	for i, o := range pending {
+		index[o] = i
+	}
+	waiting := make([]int, len(pending))
+	dependents := make([][]int, len(pending))
+	for i, o := range pending {
+		for _, e := range o.deps {
+			if j, ok := index[e.To]; ok && j != i {
+				waiting[i]++
+				dependents[j] = append(dependents[j], i)
+			}
+		}
+	}
+
+	workers := g.StartWorkers
+	if workers > len(pending) {
+		workers = len(pending)
+	}
+	ready := make(chan int, len(pending))
+	done := make(chan startResult, len(pending))
+	var failed atomic.Bool
+	var wg sync.WaitGroup
+	for w := 1; w <= workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := range ready {
+				if failed.Load() {
+					done <- startResult{i: i, skipped: true}
+					continue
+				}
+				done <- startResult{i: i, err: g.startRecover(pending[i], w)}
+			}
+		}(w)
+	}
+
+	outstanding := 0
+	for i := range pending {
+		if waiting[i] == 0 {
+			ready <- i
+			outstanding++
+		}
+	}
+	var errs []error
+	for outstanding > 0 {
+		r := <-done
+		outstanding--
+		if r.skipped {
+			continue
+		}
+		if r.err != nil {
+			failed.Store(true)
+			errs = append(errs, r.err)
+			continue
+		}
+		pending[r.i].deferred = false
+		if failed.Load() {
+			continue
+		}
+		for _, d := range dependents[r.i] {
+			waiting[d]--
+			if waiting[d] == 0 {
+				ready <- d
+				outstanding++
+			}
+		}
+	}
+	close(ready)
+	wg.Wait()
+	return errors.Join(errs...)
+}
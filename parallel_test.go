@@ -0,0 +1,152 @@
+package inji
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+type SlowClient struct {
+	started bool
+	closed  bool
+}
+
+func (c *SlowClient) Start() error {
+	time.Sleep(100 * time.Millisecond)
+	c.started = true
+	return nil
+}
+
+func (c *SlowClient) Close() {
+	c.closed = true
+}
+
+type ClientA struct{ SlowClient }
+type ClientB struct{ SlowClient }
+type ClientC struct{ SlowClient }
+type ClientD struct{ SlowClient }
+
+type FailClient struct {
+	Conf string `inject:"conf"`
+}
+
+func (c *FailClient) Start() error {
+	time.Sleep(50 * time.Millisecond)
+	return errors.New("connect fail")
+}
+
+type PanicClient struct {
+	Conf string `inject:"conf"`
+}
+
+func (c *PanicClient) Start() error {
+	panic("connect panic")
+}
+
+type ClientHost struct {
+	A      *ClientA `inject:""`
+	B      *ClientB `inject:""`
+	C      *ClientC `inject:""`
+	D      *ClientD `inject:""`
+	allSet bool
+}
+
+func (h *ClientHost) Start() error {
+	h.allSet = h.A.started && h.B.started && h.C.started && h.D.started
+	return nil
+}
+
+func TestStartWorkers(t *testing.T) {
+	fmt.Println("############## test start workers")
+	g := NewGraph()
+	defer g.Close()
+	g.StartWorkers = 4
+
+	st := time.Now()
+	h := g.RegisterOrFail("host", (*ClientHost)(nil)).(*ClientHost)
+	cost := time.Since(st)
+	if cost >= 300*time.Millisecond {
+		t.Error("independent clients should start concurrently", cost)
+		return
+	}
+	if !h.allSet {
+		t.Error("host should start after its dependencies")
+		return
+	}
+
+	spans := g.StartTimeline()
+	if len(spans) != 5 {
+		t.Error("invalid timeline", spans)
+		return
+	}
+	last := spans[len(spans)-1]
+	if last.Name != "host" {
+		t.Error("host should start last", spans)
+		return
+	}
+	for _, s := range spans[:4] {
+		if s.Worker <= 0 || s.Err != nil || s.Duration() < 100*time.Millisecond || s.End.After(last.Begin) {
+			t.Error("invalid span", s)
+			return
+		}
+	}
+
+	g.StartWorkers = 0
+	g.RegisterOrFail("client", &ClientA{})
+	spans = g.StartTimeline()
+	if len(spans) != 6 || spans[5].Worker != 0 {
+		t.Error("sequential start should be on worker 0", spans)
+	}
+}
+
+func TestStartWorkersFail(t *testing.T) {
+	fmt.Println("############## test start workers fail")
+	g := NewGraph()
+	defer g.Close()
+	g.StartWorkers = 2
+
+	type failHost struct {
+		A    *ClientA    `inject:""`
+		Fail *FailClient `inject:""`
+	}
+	g.RegisterOrFail("conf", "##conf1")
+	_, err := g.Register("host", (*failHost)(nil))
+	if err == nil || !strings.Contains(err.Error(), "connect fail") {
+		t.Error("start of FailClient should fail", err)
+		return
+	}
+	fmt.Println(err)
+	if g.Len() != 1 {
+		t.Error("registration should be rolled back", g.SPrint())
+		return
+	}
+	spans := g.StartTimeline()
+	if len(spans) != 2 {
+		t.Error("host should not be started", spans)
+	}
+}
+
+func TestStartWorkersPanic(t *testing.T) {
+	fmt.Println("############## test start workers panic")
+	g := NewGraph()
+	defer g.Close()
+	g.StartWorkers = 2
+
+	type panicHost struct {
+		A     *ClientA     `inject:""`
+		Panic *PanicClient `inject:""`
+	}
+	g.RegisterOrFail("conf", "##conf1")
+	_, err := g.Register("host", (*panicHost)(nil))
+	var se *StartError
+	if !errors.As(err, &se) || !strings.Contains(err.Error(), "connect panic") {
+		t.Error("panic of PanicClient should fail its start", err)
+		return
+	}
+	fmt.Println(err)
+	if g.Len() != 1 {
+		t.Error("registration should be rolled back", g.SPrint())
+	}
+}
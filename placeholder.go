@@ -0,0 +1,128 @@
+package inji
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+//expand resolve ${name} and ${name:default} placeholders in s,
+//name is looked up as an object of graph first, then as a config key,
+//config values found and defaults are expanded too,
+//$${ is an escaped ${ and kept as ${
+func (g *Graph) expand(s string) (string, error) {
+	return g.expandPath(s, nil)
+}
+
+//expandPath expand s found on path of placeholders,
+//a placeholder already on path is a cycle
+func (g *Graph) expandPath(s string, path []string) (string, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+	var buf bytes.Buffer
+	for {
+		i := strings.Index(s, "${")
+		if i < 0 {
+			buf.WriteString(s)
+			return buf.String(), nil
+		}
+		if i > 0 && s[i-1] == '$' {
+			buf.WriteString(s[:i-1] + "${")
+			s = s[i+2:]
+			continue
+		}
+		buf.WriteString(s[:i])
+		end := placeholderEnd(s, i+2)
+		if end < 0 {
+			return "", fmt.Errorf("placeholder not closed,value=%s", s[i:])
+		}
+		key, def, hasDefault := s[i+2:end], "", false
+		if j := strings.Index(key, ":"); j >= 0 {
+			key, def, hasDefault = key[:j], key[j+1:], true
+		}
+		v, err := g.placeholder(strings.TrimSpace(key), def, hasDefault, path)
+		if err != nil {
+			return "", err
+		}
+		buf.WriteString(v)
+		s = s[end+1:]
+	}
+}
+
+//placeholderEnd find the '}' closing the placeholder started before i,
+//placeholders nested in defaults are skipped
+func placeholderEnd(s string, i int) int {
+	depth := 0
+	for ; i < len(s); i++ {
+		switch {
+		case strings.HasPrefix(s[i:], "${"):
+			depth++
+			i++
+		case s[i] == '}':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		}
+	}
+	return -1
+}
+
+func (g *Graph) placeholder(key string, def string, hasDefault bool, path []string) (string, error) {
+	for i, p := range path {
+		if p == key {
+			cycle := append(append([]string{}, path[i:]...), key)
+			return "", fmt.Errorf("placeholder cycle found,path=%s", strings.Join(cycle, " -> "))
+		}
+	}
+	v, ok, expanded, err := g.placeholderValue(key)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		if !hasDefault {
+			return "", fmt.Errorf("placeholder key=%s not resolved", key)
+		}
+		return g.expandPath(def, path)
+	}
+	if expanded {
+		return v, nil
+	}
+	return g.expandPath(v, append(path[:len(path):len(path)], key))
+}
+
+//placeholderValue find key in graph, then in config,
+//only scalar values can be used, expanded is true for objects
+//of graph, whose strings are expanded when registered
+func (g *Graph) placeholderValue(key string) (v string, ok bool, expanded bool, err error) {
+	var value interface{}
+	if found, ok := g.find(key); ok && found.Value != nil {
+		value = found.Value
+		expanded = true
+	} else if v, ok := g.lookupConfig(key); ok {
+		value = v
+	} else {
+		return "", false, false, nil
+	}
+	s, err := convertString(reflect.ValueOf(value))
+	if err != nil {
+		return "", false, false, fmt.Errorf("placeholder key=%s is %T,err=%v", key, value, err)
+	}
+	return s, true, expanded, nil
+}
+
+//injectDefault set field f of o with def, the value of its `default` tag
+func (g *Graph) injectDefault(o *Object, def string, f reflect.StructField, vf reflect.Value) error {
+	s, err := g.expand(def)
+	if err != nil {
+		return fmt.Errorf("default of field=%s not valid in object %s:%v,err=%w", f.Name, o.Name, o.reflectType, err)
+	}
+	v, err := g.convert(reflect.ValueOf(s), f.Type)
+	if err != nil {
+		return fmt.Errorf("default of field=%s not valid in object %s:%v,err=%w", f.Name, o.Name, o.reflectType, err)
+	}
+	vf.Set(v)
+	return nil
+}
@@ -0,0 +1,126 @@
+package inji
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+type PathUser struct {
+	Data    string        `inject:"data" default:"${home}/data"`
+	Log     string        `inject:"log" default:"${log.dir:${home}/log}"`
+	Timeout time.Duration `inject:"timeout" default:"${http.timeout}"`
+	URL     string        `config:"url"`
+	Port    int           `inject:"port"`
+}
+
+func TestPlaceholder(t *testing.T) {
+	fmt.Println("############## test placeholder")
+	g := NewGraph()
+	defer g.Close()
+
+	err := g.LoadConfig(MapSource(map[string]interface{}{
+		"http.host":    "localhost",
+		"http.port":    8080,
+		"http.timeout": "3s",
+		"url":          "http://${http.host}:${http.port}",
+	}))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	g.RegisterOrFail("home", "/home/${user:nobody}")
+	if home, _ := g.Find("home"); home.Value != "/home/nobody" {
+		t.Error("default of placeholder should be used", home.Value)
+		return
+	}
+	g.RegisterOrFail("path_string", "${home}/${http.host}")
+	if path, _ := g.Find("path_string"); path.Value != "/home/nobody/localhost" {
+		t.Error("placeholders should be resolved by objects and config", path.Value)
+		return
+	}
+	g.RegisterOrFail("port", "${http.port}")
+
+	u := g.RegisterOrFail("user", (*PathUser)(nil)).(*PathUser)
+	if u.Data != "/home/nobody/data" || u.Log != "/home/nobody/log" || u.Timeout != 3*time.Second {
+		t.Error("invalid defaults", u)
+		return
+	}
+	if u.URL != "http://localhost:8080" || u.Port != 8080 {
+		t.Error("invalid placeholders", u)
+		return
+	}
+}
+
+func TestPlaceholderInvalid(t *testing.T) {
+	fmt.Println("############## test placeholder invalid")
+	g := NewGraph()
+	defer g.Close()
+
+	_, err := g.Register("home", "/home/${user}")
+	if err == nil || !strings.Contains(err.Error(), "key=user") {
+		t.Error("unresolved placeholder should name the key", err)
+		return
+	}
+	fmt.Println(err)
+	if _, ok := g.Find("home"); ok {
+		t.Error("home should not be registered")
+		return
+	}
+
+	_, err = g.Register("unclosed", "${user")
+	if err == nil {
+		t.Error("unclosed placeholder should fail")
+		return
+	}
+	fmt.Println(err)
+
+	err = g.LoadConfig(MapSource(map[string]interface{}{
+		"a": "${b}",
+		"b": "x${c:${a}}",
+	}))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, err = g.Register("cycle", "${a}")
+	if err == nil || !strings.Contains(err.Error(), "a -> b -> a") {
+		t.Error("placeholder cycle should be found", err)
+		return
+	}
+	fmt.Println(err)
+
+	type cycleUser struct {
+		A string `config:"a"`
+	}
+	_, err = g.Register("cycle_user", (*cycleUser)(nil))
+	if err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Error("placeholder cycle should be found", err)
+		return
+	}
+	fmt.Println(err)
+}
+
+func TestPlaceholderEscape(t *testing.T) {
+	fmt.Println("############## test placeholder escape")
+	g := NewTestGraph(t)
+	g.RegisterOrFail("name", "inji")
+
+	tpl := g.RegisterOrFail("tpl", "Hello $${name}, ${name}")
+	if tpl != "Hello ${name}, inji" {
+		t.Error("escaped placeholder should be kept", tpl)
+		return
+	}
+	//the registered value is expanded once, never again
+	msg := g.RegisterOrFail("msg", "${tpl}!")
+	if msg != "Hello ${name}, inji!" {
+		t.Error("value of an object should not be expanded again", msg)
+		return
+	}
+	def := g.RegisterOrFail("def", "${missing:$${x}}")
+	if def != "${x}" {
+		t.Error("escaped placeholder in default should be kept", def)
+	}
+}
@@ -0,0 +1,181 @@
+package inji
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+func (g *Graph) ProvideOrFail(name string, fn interface{}, opts ...Option) interface{} {
+	v, err := g.Provide(name, fn, opts...)
+	if err != nil {
+		if g.Logger != nil {
+			g.Logger.Error(err)
+		}
+		panic(fmt.Errorf("provide fail,name=%v,err=%w", name, err))
+	}
+	return v
+}
+
+//Provide register the object returned by fn,
+//fn must be a func(deps...) T or func(deps...) (T, error),
+//every dep is found from graph by type, struct ptr deps
+//are auto created if not found,
+//a struct(not a pointer) dep is a parameter struct, its fields
+//are injected by inject tags just like a registered struct, e.g.
+//	func(p struct {
+//		Timeout int `inject:"timeout"`
+//	}) (*http.Client, error)
+//the returned object is started and closed like a registered one
+func (g *Graph) Provide(name string, fn interface{}, opts ...Option) (interface{}, error) {
+	g.l.Lock()
+	defer g.l.Unlock()
+	return g.transact(func() (interface{}, error) {
+		return g.provide(name, fn, newOptions(opts))
+	})
+}
+
+func (g *Graph) provide(name string, fn interface{}, opts options) (interface{}, error) {
+	if fn == nil {
+		return nil, fmt.Errorf("%w,provider can not be nil,name=%s", ErrInvalidRegistration, name)
+	}
+	fv := reflect.ValueOf(fn)
+	ft := fv.Type()
+	if ft.Kind() != reflect.Func || fv.IsNil() {
+		return nil, fmt.Errorf("%w,provider must be a func,name=%s,type=%v", ErrInvalidRegistration, name, ft)
+	}
+	if ft.NumOut() < 1 || ft.NumOut() > 2 || (ft.NumOut() == 2 && ft.Out(1) != errorType) {
+		return nil, fmt.Errorf("%w,provider must return T or (T, error),name=%s,type=%v", ErrInvalidRegistration, name, ft)
+	}
+	if ft.IsVariadic() {
+		return nil, fmt.Errorf("%w,provider can not be variadic,name=%s,type=%v", ErrInvalidRegistration, name, ft)
+	}
+
+	reflectType := ft.Out(0)
+	if name == "" {
+		if !isStructPtr(reflectType) {
+			return nil, fmt.Errorf("%w,name can not be empty,name=%s,type=%v", ErrInvalidRegistration, name, reflectType)
+		}
+		name = getTypeName(reflectType)
+	}
+
+	//already registered, objects of parent can be hidden
+	found, ok := g.findLocal(name)
+	if ok && found.override {
+		return found.Value, nil
+	}
+	if ok {
+		return nil, fmt.Errorf("%w,name=%s,type=%v,found=%v", ErrAlreadyRegistered, name, reflectType, found)
+	}
+
+	o := &Object{
+		Name:        name,
+		reflectType: reflectType,
+	}
+	opts.apply(o)
+	frame, err := g.enter(name, reflectType)
+	if err != nil {
+		return nil, err
+	}
+	g.emit(Event{Kind: EventBeforeResolve, Object: o})
+	begin := time.Now()
+	args := make([]reflect.Value, ft.NumIn())
+	err = func() error {
+		defer g.leave()
+		for i := range args {
+			var err error
+			args[i], err = g.providerArg(o, frame, ft.In(i), i)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}()
+	if err != nil {
+		g.emit(Event{Kind: EventAfterResolve, Object: o, Duration: time.Since(begin), Err: err})
+		return nil, err
+	}
+
+	out := fv.Call(args)
+	g.resolved(o, frame, begin)
+	g.emit(Event{Kind: EventAfterResolve, Object: o, Duration: time.Since(begin)})
+	if len(out) == 2 && !out[1].IsNil() {
+		return nil, fmt.Errorf("provide object fail,name=%v,err=%w", name, out[1].Interface().(error))
+	}
+	value := out[0].Interface()
+	if value == nil || (canNil(value) && isNil(value)) {
+		return nil, fmt.Errorf("%w,provider returned nil,name=%s,type=%v", ErrInvalidRegistration, name, reflectType)
+	}
+	o.Value = value
+	o.reflectType = reflect.TypeOf(value)
+
+	err = g.checkImplements(o)
+	if err != nil {
+		return nil, err
+	}
+	err = g.startOrDefer(o)
+	if err != nil {
+		return nil, err
+	}
+
+	//a provider is the only source of its type,
+	//so it can be found by type like a singleton
+	if isStructPtr(o.reflectType) {
+		g.setboth(name, o)
+	} else {
+		g.set(name, o)
+	}
+	g.join(o)
+	g.bindImplements(o)
+	g.track(o)
+	err = g.decorate(o)
+	if err != nil {
+		return nil, err
+	}
+	if g.Logger != nil && g.Logger.IsDebugEnabled() {
+		toLogJson, toLogErr := json.Marshal(o.Value)
+		g.Logger.Debug("provided!name=%s,t=%v,v=%v,jsonerr=%v", name, o.reflectType, string(toLogJson), toLogErr)
+	}
+	return o.Value, nil
+}
+
+//providerArg resolve the i-th parameter of the provider of o
+func (g *Graph) providerArg(o *Object, frame *resolveFrame, t reflect.Type, i int) (reflect.Value, error) {
+	if t.Kind() == reflect.Struct {
+		p := reflect.New(t)
+		err := g.fill(o, frame, p, false)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return p.Elem(), nil
+	}
+
+	frame.field = fmt.Sprintf("arg%d", i)
+	found, ok := g.findByType(t)
+	created := false
+	if !ok && isStructPtr(t) {
+		created = true
+		_, err := g.register("", reflect.NewAt(t.Elem(), nil).Interface(), false, false, options{})
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		found, ok = g.findByType(t)
+	}
+	if !ok || found == nil {
+		return reflect.Value{}, &MissingDependencyError{Object: o.Name, ObjectType: o.reflectType, Field: frame.field, Type: t,
+			Path: g.resolvePath(), Suggestions: g.suggest("", t)}
+	}
+	if !found.reflectType.AssignableTo(t) {
+		return reflect.Value{}, &TypeMismatchError{Name: found.Name, Want: t, Got: found.reflectType,
+			Object: o.Name, ObjectType: o.reflectType, Field: frame.field, Path: g.resolvePath()}
+	}
+	o.deps = append(o.deps, &Edge{
+		Field:   frame.field,
+		Created: created,
+		To:      found,
+	})
+	return reflect.ValueOf(found.value()), nil
+}
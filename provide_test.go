@@ -0,0 +1,138 @@
+package inji
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type Conn struct {
+	Addr    string
+	Timeout int
+	started bool
+	closed  bool
+}
+
+func (c *Conn) Start() error {
+	c.started = true
+	return nil
+}
+
+func (c *Conn) Close() {
+	c.closed = true
+}
+
+type ConnPool struct {
+	Conn *Conn `inject:"conn"`
+}
+
+func TestProvide(t *testing.T) {
+	fmt.Println("############## test provide")
+	InitDefault()
+	l := &Log{}
+	SetLogger(l)
+
+	RegisterOrFail("addr", "127.0.0.1:80")
+	RegisterOrFail("timeout", 3)
+	var s1 *Sin1
+	c, err := _g.Provide("conn", func(s *Sin1, p struct {
+		Addr    string `inject:"addr"`
+		Timeout int    `inject:"timeout"`
+	}) (*Conn, error) {
+		s1 = s
+		return &Conn{Addr: p.Addr, Timeout: p.Timeout}, nil
+	})
+	if err != nil {
+		t.Error("provide should not fail", err)
+		return
+	}
+	conn, ok := c.(*Conn)
+	if !ok || conn.Addr != "127.0.0.1:80" || conn.Timeout != 3 {
+		t.Error("invalid provided conn", c)
+		return
+	}
+	if !conn.started {
+		t.Error("provided conn should be started")
+		return
+	}
+	if s1 == nil {
+		t.Error("struct ptr arg should be auto created")
+		return
+	}
+	if found, ok := FindByType(reflect.TypeOf(conn)); !ok || found != conn {
+		t.Error("provided conn should be found by type", found)
+		return
+	}
+
+	pool := RegisterOrFail("pool", (*ConnPool)(nil)).(*ConnPool)
+	if pool.Conn != conn {
+		t.Error("provided conn should be injected by name", pool.Conn)
+		return
+	}
+
+	Close()
+	if !conn.closed {
+		t.Error("provided conn should be closed")
+	}
+}
+
+func TestProvideFail(t *testing.T) {
+	fmt.Println("############## test provide fail")
+	InitDefault()
+	defer Close()
+
+	_, err := _g.Provide("conn", func() (*Conn, error) {
+		return nil, errors.New("dial fail")
+	})
+	if err == nil {
+		t.Error("provider error should be returned")
+		return
+	}
+	fmt.Println(err)
+
+	_, err = _g.Provide("conn", func(p struct {
+		Addr string `inject:"addr"`
+	}) *Conn {
+		return &Conn{Addr: p.Addr}
+	})
+	if err == nil {
+		t.Error("missing dependency should fail")
+		return
+	}
+	fmt.Println(err)
+
+	_, err = _g.Provide("conn", &Conn{})
+	if err == nil {
+		t.Error("provider must be a func")
+		return
+	}
+	_, err = ProvideWith("conn", nil)
+	if err == nil {
+		t.Error("provider can not be nil")
+		return
+	}
+	if GraphLen() != 0 {
+		t.Error("nothing should be registered", GraphPrint())
+	}
+}
+
+func TestProvideOverride(t *testing.T) {
+	fmt.Println("############## test provide override")
+	g := NewTestGraph(t)
+
+	fake := &Conn{Addr: "fake"}
+	err := g.Override("conn", fake)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	called := false
+	c, err := g.Provide("conn", func() *Conn {
+		called = true
+		return &Conn{Addr: "real"}
+	})
+	if err != nil || c != fake || called {
+		t.Error("provide of overridden name should return the overriding value", c, err)
+	}
+}
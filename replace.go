@@ -0,0 +1,231 @@
+package inji
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+//Override register value on name, an object already registered on name
+//is replaced if nothing depends on it yet, then closed and removed,
+//it is kept if value fails to register, a later Register of name
+//returns the overriding value instead of failing, so Override can be
+//called before the code registering the real object, e.g. in tests
+func (g *Graph) Override(name string, value interface{}) error {
+	g.l.Lock()
+	defer g.l.Unlock()
+
+	if name == "" && isStructPtr(reflect.TypeOf(value)) {
+		name = getTypeName(reflect.TypeOf(value))
+	}
+	singleton := false
+	old, ok := g.findLocal(name)
+	var groups []string
+	if ok {
+		if consumers := g.consumers(old); len(consumers) > 0 {
+			return fmt.Errorf("object is injected into %s,use Replace,name=%s", consumers[0].Name, name)
+		}
+		singleton = g.isSingleton(old)
+		groups = old.groups
+		g.remove(old)
+		old.groups = nil
+	}
+	_, err := g.transact(func() (interface{}, error) {
+		return g.register(name, value, singleton, false, options{})
+	})
+	if err != nil {
+		if old != nil {
+			g.restore(old, singleton, groups)
+		}
+		return err
+	}
+	o, _ := g.findLocal(name)
+	o.override = true
+	g.emit(Event{Kind: EventOverride, Object: o, Old: old})
+	if old != nil {
+		err = g.closeObject(context.Background(), old)
+		if err != nil {
+			return fmt.Errorf("close overridden object fail,name=%s,err=%w", name, err)
+		}
+	}
+	return nil
+}
+
+//Replace register value on name instead of the object registered on it,
+//every field injected with the old object is injected with the new one,
+//except parameters of providers and resolved Lazy fields,
+//then the old object is closed and removed,
+//only fields of objects of g are injected again,
+//objects of its children keep the closed old object
+func (g *Graph) Replace(name string, value interface{}) error {
+	g.l.Lock()
+	defer g.l.Unlock()
+
+	old, ok := g.findLocal(name)
+	if !ok {
+		return fmt.Errorf("replace object not found,name=%s", name)
+	}
+	if old.template {
+		return fmt.Errorf("template can not be replaced,name=%s", name)
+	}
+	consumers := g.consumers(old)
+	singleton := g.isSingleton(old)
+	before := make(map[*Object]bool)
+	for _, o := range g.nodes().objects {
+		before[o] = true
+	}
+
+	g.remove(old)
+	opts := options{
+		startTimeout: old.startTimeout,
+		closeTimeout: old.closeTimeout,
+		groups:       old.groups,
+		implements:   old.implements,
+	}
+	old.groups = nil
+	_, err := g.transact(func() (interface{}, error) {
+		return g.register(name, value, singleton, false, opts)
+	})
+	if err != nil {
+		g.restore(old, singleton, opts.groups)
+		return err
+	}
+	o, _ := g.findLocal(name)
+
+	//objects created by Replace are closed after the consumers
+	var created []*Object
+	for _, c := range g.nodes().objects {
+		if !before[c] {
+			created = append(created, c)
+		}
+	}
+	var errs []error
+	for _, c := range consumers {
+		err := g.reinject(c, old, o)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(consumers) > 0 {
+		g.moveBefore(created, g.first(consumers))
+	}
+	watchL.Lock()
+	old.watchers = nil
+	watchL.Unlock()
+
+	err = g.closeObject(context.Background(), old)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("close replaced object fail,name=%s,err=%w", name, err))
+	}
+	g.emit(Event{Kind: EventReplace, Object: o, Old: old})
+	return errors.Join(errs...)
+}
+
+//consumers return the objects injected with o, on registration order
+func (g *Graph) consumers(o *Object) []*Object {
+	var ret []*Object
+	for _, c := range g.nodes().objects {
+		for _, e := range c.deps {
+			if e.To == o {
+				ret = append(ret, c)
+				break
+			}
+		}
+	}
+	return ret
+}
+
+//first return the object of objs registered first
+func (g *Graph) first(objs []*Object) *Object {
+	in := make(map[*Object]bool)
+	for _, o := range objs {
+		in[o] = true
+	}
+	iter := g.named.IterFunc()
+	for kv, ok := iter(); ok; kv, ok = iter() {
+		if o, ok := kv.Value.(*Object); ok && in[o] {
+			return o
+		}
+	}
+	return objs[0]
+}
+
+func (g *Graph) isSingleton(o *Object) bool {
+	if !isStructPtr(o.reflectType) {
+		return false
+	}
+	found, ok := g.findLocal(getTypeName(o.reflectType))
+	return ok && found == o && o.Name != getTypeName(o.reflectType)
+}
+
+//restore set o back after a failed Replace or Override
+func (g *Graph) restore(o *Object, singleton bool, groups []string) {
+	rewatch(o)
+	if singleton {
+		g.setboth(o.Name, o)
+	} else {
+		g.set(o.Name, o)
+	}
+	o.groups = groups
+	for _, group := range groups {
+		g.groups[group] = append(g.groups[group], o)
+	}
+	g.bindImplements(o)
+}
+
+//reinject set the fields of c injected with old to o,
+//old is o itself when the value of o is decorated
+func (g *Graph) reinject(c *Object, old *Object, o *Object) error {
+	v := reflect.ValueOf(c.Value)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	for _, e := range c.deps {
+		if e.To != old {
+			continue
+		}
+		field, sub := e.Field, ""
+		if i := strings.Index(field, "["); i >= 0 && strings.HasSuffix(field, "]") {
+			field, sub = field[:i], field[i+1:len(field)-1]
+		}
+		vf := v.Elem().FieldByName(field)
+		if !vf.IsValid() || !vf.CanSet() || isLazyField(vf.Type()) {
+			//provider params, or resolved Lazy fields kept until c is replaced
+			continue
+		}
+		e.To = o
+
+		switch {
+		case vf.Type().Kind() == reflect.Ptr && vf.Type().Implements(dynamicType):
+			d := vf.Interface().(dynamic)
+			nv, err := g.convert(reflect.ValueOf(o.value()), d.valueType())
+			if err != nil {
+				return fmt.Errorf("reinject field=%s of %s fail,err=%w", e.Field, c.Name, err)
+			}
+			d.store(nv.Interface())
+			if old != o {
+				watchL.Lock()
+				o.watchers = append(o.watchers, watcher{owner: c, d: d})
+				watchL.Unlock()
+			}
+		case sub != "" && vf.Kind() == reflect.Slice:
+			i, err := strconv.Atoi(sub)
+			if err != nil || i >= vf.Len() {
+				continue
+			}
+			vf.Index(i).Set(reflect.ValueOf(o.value()))
+		case sub != "" && vf.Kind() == reflect.Map:
+			vf.SetMapIndex(reflect.ValueOf(sub).Convert(vf.Type().Key()), reflect.ValueOf(o.value()))
+		default:
+			nv, err := g.convert(reflect.ValueOf(o.value()), vf.Type())
+			if err != nil {
+				return fmt.Errorf("reinject field=%s of %s fail,err=%w", e.Field, c.Name, err)
+			}
+			vf.Set(nv)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package inji
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeService struct {
+	closed bool
+}
+
+func (s *fakeService) Call() string {
+	return "fake"
+}
+
+func (s *fakeService) Close() {
+	s.closed = true
+}
+
+func TestOverride(t *testing.T) {
+	fmt.Println("############## test override")
+	g := NewTestGraph(t)
+
+	fake := &fakeService{}
+	err := g.Override("service", fake)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	real := &RealService{}
+	v, err := g.Register("service", real)
+	if err != nil || v != fake || real.started {
+		t.Error("registration of overridden name should return the overriding value", v, err)
+		return
+	}
+	u := g.RegisterOrFail("user", (*ServiceUser)(nil)).(*ServiceUser)
+	if u.Service.Call() != "fake" {
+		t.Error("consumer should get the overriding value")
+		return
+	}
+
+	err = g.Override("service", &fakeService{})
+	if err == nil || !strings.Contains(err.Error(), "use Replace") {
+		t.Error("override of consumed object should fail", err)
+		return
+	}
+
+	other := &fakeService{}
+	g.RegisterOrFail("other", other)
+	err = g.Override("other", &fakeService{})
+	if err != nil || !other.closed {
+		t.Error("overridden object should be closed", err)
+	}
+}
+
+type badFake struct {
+	Missing string `inject:"not_registered"`
+}
+
+func TestOverrideFail(t *testing.T) {
+	fmt.Println("############## test override fail")
+	g := NewTestGraph(t)
+	fake := g.RegisterOrFail("f", &fakeService{}).(*fakeService)
+	g.RegisterOrFail("n", 1)
+	du := g.RegisterOrFail("dynamic_user", (*DynamicUser)(nil)).(*DynamicUser)
+
+	err := g.Override("f", (*badFake)(nil))
+	if err == nil {
+		t.Error("override should fail")
+		return
+	}
+	o, ok := g.Find("f")
+	if !ok || o.Value != fake || fake.closed {
+		t.Error("overridden object should be kept on failure", o)
+		return
+	}
+
+	err = g.Override("dynamic_user", (*badFake)(nil))
+	if err == nil {
+		t.Error("override should fail")
+		return
+	}
+	err = g.Update("n", 2)
+	if err != nil || du.N.Get() != 2 {
+		t.Error("kept object should still follow its values", du.N.Get(), err)
+	}
+}
+
+func TestReplace(t *testing.T) {
+	fmt.Println("############## test replace")
+	g := NewTestGraph(t)
+
+	real := g.RegisterOrFail("service", &RealService{}).(*RealService)
+	u := g.RegisterOrFail("user", (*ServiceUser)(nil)).(*ServiceUser)
+	g.RegisterOrFail("timeout", time.Second)
+	g.RegisterOrFail("limit", 10)
+	r := g.RegisterOrFail("reload", (*ReloadUser)(nil)).(*ReloadUser)
+	g.RegisterOrFail("conf", "##conf1")
+	g.RegisterOrFailGroup("plugins", &PluginA{})
+	host := g.RegisterOrFail("host", (*PluginHost)(nil)).(*PluginHost)
+
+	fake := &fakeService{}
+	err := g.Replace("service", fake)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if u.Service != fake || !real.closed {
+		t.Error("consumer should be reinjected and old object closed", u.Service)
+		return
+	}
+	if found, _ := g.Find("service"); found.Value != fake || g.consumers(found)[0].Name != "user" {
+		t.Error("edges should point to the new object")
+		return
+	}
+
+	err = g.Replace("limit", 20)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if r.Limit.Get() != 20 || r.Fixed != 20 {
+		t.Error("value fields should be reinjected", r.Limit, r.Fixed)
+		return
+	}
+	err = g.Update("limit", 30)
+	if err != nil || r.Limit.Get() != 30 {
+		t.Error("dynamic value should follow the new object", err, r.Limit)
+		return
+	}
+
+	b := &PluginB{}
+	err = g.Replace("plugins.0", b)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if host.List[0] != b || host.Map["plugins.0"] != b || b.Conf != "##conf1" {
+		t.Error("group fields should be reinjected", host.List, host.Map)
+		return
+	}
+	if len(g.Members("plugins")) != 1 || g.Members("plugins")[0].Value != b {
+		t.Error("new object should join the groups of the old one", g.Members("plugins"))
+		return
+	}
+
+	err = g.Replace("none", 1)
+	if err == nil {
+		t.Error("none is not registered")
+	}
+}
+
+type cleanupT struct {
+	cleanups []func()
+	errs     []string
+}
+
+func (c *cleanupT) Cleanup(fn func()) {
+	c.cleanups = append(c.cleanups, fn)
+}
+
+func (c *cleanupT) Errorf(format string, args ...interface{}) {
+	c.errs = append(c.errs, fmt.Sprintf(format, args...))
+}
+
+func TestNewTestGraph(t *testing.T) {
+	fmt.Println("############## test new test graph")
+	ct := &cleanupT{}
+	g := NewTestGraph(ct)
+	s := g.RegisterOrFail("service", &RealService{}).(*RealService)
+	g.RegisterOrFail("db", &ErrCloser{})
+	if len(ct.cleanups) != 1 {
+		t.Error("close should be registered as cleanup")
+		return
+	}
+	ct.cleanups[0]()
+	if !s.closed || len(ct.errs) != 1 {
+		t.Error("graph should be closed and close errors reported", ct.errs)
+	}
+}
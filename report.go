@@ -0,0 +1,127 @@
+package inji
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+)
+
+//ObjectTiming is the time an object spent on resolving its dependencies,
+//on Start and on Close, Resolve never counts the objects created meanwhile
+type ObjectTiming struct {
+	Name    string
+	Type    reflect.Type
+	Resolve time.Duration
+	Start   time.Duration
+	Close   time.Duration
+}
+
+//Startup is the time the object took before it can be injected
+func (t ObjectTiming) Startup() time.Duration {
+	return t.Resolve + t.Start
+}
+
+//Timing return the recorded times of o
+func (o *Object) Timing() ObjectTiming {
+	return ObjectTiming{Name: o.Name, Type: o.reflectType, Resolve: o.resolveTime, Start: o.startTime, Close: o.closeTime}
+}
+
+//StartupReport is the startup times of every object of a graph
+type StartupReport struct {
+	//Objects sorted by Startup, the slowest first
+	Objects []ObjectTiming
+	//CriticalPath is the chain of dependencies with the longest Startup in total,
+	//a dependency comes before the objects it is injected into
+	CriticalPath []ObjectTiming
+	//Critical is the total Startup of CriticalPath,
+	//the least time to start the graph even with StartWorkers
+	Critical time.Duration
+}
+
+func (r *StartupReport) String() string {
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "critical path(%v):\n", r.Critical)
+	for _, t := range r.CriticalPath {
+		fmt.Fprintf(buf, "\t%s resolve=%v,start=%v\n", t.Name, t.Resolve, t.Start)
+	}
+	fmt.Fprintf(buf, "objects:\n")
+	for _, t := range r.Objects {
+		fmt.Fprintf(buf, "\t%s resolve=%v,start=%v,close=%v\n", t.Name, t.Resolve, t.Start, t.Close)
+	}
+	return buf.String()
+}
+
+//StartupReport return the recorded times of every object and
+//the critical path over the dependency graph
+func (g *Graph) StartupReport() *StartupReport {
+	g.l.RLock()
+	defer g.l.RUnlock()
+
+	n := g.nodes()
+	r := &StartupReport{}
+	for _, o := range n.objects {
+		r.Objects = append(r.Objects, o.Timing())
+	}
+	sort.SliceStable(r.Objects, func(i, j int) bool {
+		return r.Objects[i].Startup() > r.Objects[j].Startup()
+	})
+
+	//longest is the total Startup of the slowest chain ending at an object,
+	//next is the dependency the chain goes through
+	longest := make(map[*Object]time.Duration, len(n.objects))
+	next := make(map[*Object]*Object, len(n.objects))
+	var walk func(o *Object) time.Duration
+	walk = func(o *Object) time.Duration {
+		if d, ok := longest[o]; ok {
+			return d
+		}
+		//objects are acyclic, this only guards against a bug
+		longest[o] = 0
+		var max time.Duration
+		for _, e := range o.deps {
+			d := walk(e.To)
+			if d > max || next[o] == nil {
+				max = d
+				next[o] = e.To
+			}
+		}
+		longest[o] = max + o.Timing().Startup()
+		return longest[o]
+	}
+	var last *Object
+	for _, o := range n.objects {
+		if walk(o) > r.Critical || last == nil {
+			r.Critical = longest[o]
+			last = o
+		}
+	}
+	for o := last; o != nil; o = next[o] {
+		r.CriticalPath = append([]ObjectTiming{o.Timing()}, r.CriticalPath...)
+	}
+	return r
+}
+
+//resolved record the time o spent on resolving since begin,
+//the time is excluded from the object being resolved by then
+func (g *Graph) resolved(o *Object, frame *resolveFrame, begin time.Time) {
+	d := time.Since(begin)
+	o.resolveTime = d - frame.nested
+	if n := len(g.resolving); n > 0 {
+		g.resolving[n-1].nested += d
+	}
+}
+
+//started record the time o spent on Start, a start on the registering
+//goroutine is excluded from the object being resolved by then
+func (g *Graph) started(o *Object, worker int, d time.Duration) {
+	o.startTime = d
+	if worker != 0 {
+		//g.resolving belongs to the registering goroutine
+		return
+	}
+	if n := len(g.resolving); n > 0 {
+		g.resolving[n-1].nested += d
+	}
+}
@@ -0,0 +1,120 @@
+package inji
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+type SlowStore struct {
+	closed bool
+}
+
+func (s *SlowStore) Start() error {
+	time.Sleep(200 * time.Millisecond)
+	return nil
+}
+
+func (s *SlowStore) Close() {
+	time.Sleep(5 * time.Millisecond)
+	s.closed = true
+}
+
+type SlowCache struct {
+	Store *SlowStore `inject:""`
+}
+
+func (c *SlowCache) Start() error {
+	time.Sleep(20 * time.Millisecond)
+	return nil
+}
+
+type FastClient struct {
+}
+
+func (c *FastClient) Start() error {
+	time.Sleep(5 * time.Millisecond)
+	return nil
+}
+
+type ReportApp struct {
+	Cache  *SlowCache  `inject:""`
+	Client *FastClient `inject:""`
+}
+
+//errorLog keep the errors logged
+type errorLog struct {
+	Log
+	errors []string
+}
+
+func (l *errorLog) Error(format interface{}, args ...interface{}) error {
+	f, _ := format.(string)
+	l.errors = append(l.errors, fmt.Sprintf(f, args...))
+	return nil
+}
+
+func TestStartupReport(t *testing.T) {
+	fmt.Println("############## test startup report")
+	g := NewGraph()
+	l := &errorLog{}
+	g.Logger = l
+	g.SlowStartThreshold = 100 * time.Millisecond
+
+	g.RegisterOrFail("app", (*ReportApp)(nil))
+	r := g.StartupReport()
+	fmt.Println(r)
+
+	if len(r.Objects) != 4 || r.Objects[0].Name != "*github.com/teou/inji.SlowStore" {
+		t.Error("objects should be sorted by startup", r.Objects)
+		return
+	}
+	var path []string
+	for _, o := range r.CriticalPath {
+		path = append(path, o.Name)
+	}
+	if strings.Join(path, ",") != "*github.com/teou/inji.SlowStore,*github.com/teou/inji.SlowCache,app" {
+		t.Error("invalid critical path", path)
+		return
+	}
+	if r.Critical < 220*time.Millisecond {
+		t.Error("invalid critical time", r.Critical)
+		return
+	}
+
+	app, _ := g.Find("app")
+	if app.Timing().Resolve >= 100*time.Millisecond {
+		t.Error("resolve time should not count created objects", app.Timing())
+		return
+	}
+	cache, _ := g.Find("*github.com/teou/inji.SlowCache")
+	if cache.Timing().Start < 20*time.Millisecond {
+		t.Error("invalid start time", cache.Timing())
+		return
+	}
+
+	if len(l.errors) != 1 || !strings.Contains(l.errors[0], "name=*github.com/teou/inji.SlowStore") {
+		t.Error("only the slow store should be logged", l.errors)
+		return
+	}
+
+	store, _ := g.Find("*github.com/teou/inji.SlowStore")
+	g.Close()
+	if store.Timing().Close < 5*time.Millisecond {
+		t.Error("invalid close time", store.Timing())
+	}
+}
+
+func TestSlowStartDisabled(t *testing.T) {
+	fmt.Println("############## test slow start disabled")
+	g := NewTestGraph(t)
+	l := &errorLog{}
+	g.Logger = l
+	g.SlowStartThreshold = -1
+
+	g.RegisterOrFail("store", (*SlowStore)(nil))
+	if len(l.errors) != 0 {
+		t.Error("slow start should not be logged", l.errors)
+	}
+}
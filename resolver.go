@@ -0,0 +1,173 @@
+package inji
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+//Resolver let an object find and register objects while it is being
+//started, when the graph lock is held by the registration starting it,
+//get it in StartableContext.Start by
+//	r, _ := inji.ResolverFrom(ctx)
+//a Resolver can only be used before Start returns
+type Resolver struct {
+	g *Graph
+	//l is held while the resolver is used and guard done
+	l    sync.Mutex
+	done bool
+	//nested is created for a Start called by another resolver,
+	//which already holds g.reentry
+	nested bool
+}
+
+type resolverKey struct{}
+
+//ResolverFrom return the Resolver of the object being started with ctx
+func ResolverFrom(ctx context.Context) (*Resolver, bool) {
+	r, ok := ctx.Value(resolverKey{}).(*Resolver)
+	return r, ok
+}
+
+//newResolver create the resolver of a start,
+//worker > 0 is a start of StartWorkers, never nested
+func (g *Graph) newResolver(worker int) *Resolver {
+	return &Resolver{g: g, nested: worker == 0 && g.reentered > 0}
+}
+
+//NewChild create a child of the graph like Graph.NewChild,
+//which can be used in Start, where the graph is locked by the registration
+//starting the object, a child created by Graph.NewChild in Start
+//deadlocks once it looks up its parent, the child created by r
+//reads the parent through r until Start returns, then as usual
+func (r *Resolver) NewChild() (*Graph, error) {
+	err := r.enter()
+	if err != nil {
+		return nil, err
+	}
+	defer r.leave()
+	c := r.g.NewChild()
+	c.via = r
+	return c, nil
+}
+
+//readParent call fn with the parent of g, which is read locked
+//unless the Start of the resolver of g is still running
+func (g *Graph) readParent(fn func(p *Graph)) {
+	if r := g.via; r != nil && r.enter() == nil {
+		defer r.leave()
+		fn(g.parent)
+		return
+	}
+	g.parent.l.RLock()
+	defer g.parent.l.RUnlock()
+	fn(g.parent)
+}
+
+//setStarting record r as the resolver of o while o is being started,
+//a nil r ends the start
+func (g *Graph) setStarting(o *Object, r *Resolver) {
+	g.startingL.Lock()
+	defer g.startingL.Unlock()
+	if r == nil {
+		delete(g.starting, o)
+		return
+	}
+	if g.starting == nil {
+		g.starting = make(map[*Object]*Resolver)
+	}
+	g.starting[o] = r
+}
+
+//startingResolver return the resolver of o if o is being started
+func (g *Graph) startingResolver(o *Object) (*Resolver, bool) {
+	g.startingL.Lock()
+	defer g.startingL.Unlock()
+	r, ok := g.starting[o]
+	return r, ok
+}
+
+func (r *Resolver) enter() error {
+	r.l.Lock()
+	if r.done {
+		r.l.Unlock()
+		return fmt.Errorf("resolver can only be used before Start returns")
+	}
+	if !r.nested {
+		r.g.reentry.Lock()
+		r.g.reentered++
+	}
+	return nil
+}
+
+func (r *Resolver) leave() {
+	if !r.nested {
+		r.g.reentered--
+		r.g.reentry.Unlock()
+	}
+	r.l.Unlock()
+}
+
+//close wait for the running call and disable r
+func (r *Resolver) close() {
+	r.l.Lock()
+	r.done = true
+	r.l.Unlock()
+}
+
+func (r *Resolver) Find(name string) (*Object, bool) {
+	if r.enter() != nil {
+		return nil, false
+	}
+	defer r.leave()
+	return r.g.find(name)
+}
+
+func (r *Resolver) FindByType(t reflect.Type) (*Object, bool) {
+	if r.enter() != nil {
+		return nil, false
+	}
+	defer r.leave()
+	return r.g.findByType(t)
+}
+
+//Register register value like Graph.RegisterWith,
+//the objects it creates are started one by one,
+//and rolled back with the registration starting r
+func (r *Resolver) Register(name string, value interface{}, opts ...Option) (interface{}, error) {
+	err := r.enter()
+	if err != nil {
+		return nil, err
+	}
+	defer r.leave()
+	return r.g.nestedTransact(func() (interface{}, error) {
+		return r.g.register(name, value, false, false, newOptions(opts))
+	})
+}
+
+//nestedTransact run fn as a registration inside the running one,
+//objects of fn are rolled back if fn fails, otherwise they join
+//the running registration
+func (g *Graph) nestedTransact(fn func() (interface{}, error)) (interface{}, error) {
+	outer := g.tx
+	tx := &regTx{starting: true}
+	g.tx = tx
+	returned := false
+	defer func() {
+		if !returned {
+			g.tx = outer
+			g.rollback(tx, errPanic)
+		}
+	}()
+	v, err := fn()
+	returned = true
+	g.tx = outer
+	if err != nil {
+		return nil, g.rollback(tx, err)
+	}
+	if outer != nil {
+		outer.objects = append(outer.objects, tx.objects...)
+	}
+	return v, nil
+}
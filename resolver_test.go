@@ -0,0 +1,192 @@
+package inji
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type Worker struct {
+	Conf string `inject:"conf"`
+	Host string
+}
+
+func (w *Worker) Start(ctx context.Context) error {
+	r, ok := ResolverFrom(ctx)
+	if !ok {
+		return errors.New("no resolver")
+	}
+	host, ok := r.Find("host")
+	if !ok {
+		return errors.New("host not found")
+	}
+	w.Host = host.Value.(string)
+	return nil
+}
+
+type Manager struct {
+	Conf     string `inject:"conf"`
+	Name     string
+	workers  []*Worker
+	resolver *Resolver
+}
+
+func (m *Manager) Start(ctx context.Context) error {
+	r, _ := ResolverFrom(ctx)
+	m.resolver = r
+	if m.Name == "" {
+		m.Name = fmt.Sprintf("%p", m)
+	}
+	for i := 0; i < 2; i++ {
+		w, err := r.Register(fmt.Sprintf("%s.worker.%d", m.Name, i), (*Worker)(nil))
+		if err != nil {
+			return err
+		}
+		m.workers = append(m.workers, w.(*Worker))
+	}
+	if _, ok := r.FindByType(reflect.TypeOf((*Worker)(nil))); ok {
+		return errors.New("workers are not singletons")
+	}
+	return nil
+}
+
+func TestResolver(t *testing.T) {
+	fmt.Println("############## test resolver")
+	g := NewGraph()
+	defer g.Close()
+
+	g.RegisterOrFail("conf", "##conf1")
+	g.RegisterOrFail("host", "localhost")
+	m := g.RegisterOrFail("manager", &Manager{Name: "m"}).(*Manager)
+	if len(m.workers) != 2 || m.workers[0].Host != "localhost" || m.workers[1].Conf != "##conf1" {
+		t.Error("workers should be registered and started inside Start", m.workers)
+		return
+	}
+	if _, ok := g.Find("m.worker.1"); !ok {
+		t.Error("workers should be in graph")
+		return
+	}
+	if _, err := m.resolver.Register("late", "x"); err == nil {
+		t.Error("resolver should not be used after Start")
+		return
+	}
+
+	g2 := NewGraph()
+	defer g2.Close()
+	g2.RegisterOrFail("conf", "##conf1")
+	_, err := g2.Register("failed", &Manager{Name: "f"})
+	if err == nil {
+		t.Error("worker should fail without host")
+		return
+	}
+	fmt.Println(err)
+	if _, ok := g2.Find("f.worker.0"); ok || g2.Len() != 1 {
+		t.Error("nested registration should be rolled back")
+		return
+	}
+}
+
+func TestResolverConcurrent(t *testing.T) {
+	fmt.Println("############## test resolver concurrent")
+	g := NewGraph()
+	defer g.Close()
+	g.StartWorkers = 4
+
+	g.RegisterOrFail("conf", "##conf1")
+	g.RegisterOrFail("host", "localhost")
+	type managers struct {
+		A *Manager `inject:"a"`
+		B *Manager `inject:"b"`
+		C *Manager `inject:"c"`
+	}
+	var wg sync.WaitGroup
+	errs := make(chan error, 4)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			child := g.NewChild()
+			defer child.Close()
+			_, err := child.Register("managers", (*managers)(nil))
+			if err == nil {
+				_, err = g.Register(fmt.Sprintf("manager%d", i), &Manager{Name: fmt.Sprint(i)})
+			}
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	}
+	if _, ok := g.Find("3.worker.1"); !ok {
+		t.Error("workers should be registered")
+	}
+}
+
+type Lodger struct {
+	Conf string `inject:"conf"`
+}
+
+type Lodgers struct {
+	child  *Graph
+	tenant *Lodger
+}
+
+func (ts *Lodgers) Start(ctx context.Context) error {
+	r, _ := ResolverFrom(ctx)
+	c, err := r.NewChild()
+	if err != nil {
+		return err
+	}
+	t, err := c.Register("lodger", (*Lodger)(nil))
+	if err != nil {
+		return err
+	}
+	ts.child = c
+	ts.tenant = t.(*Lodger)
+	return nil
+}
+
+func TestResolverNewChild(t *testing.T) {
+	fmt.Println("############## test resolver new child")
+	g := NewGraph()
+	g.RegisterOrFail("conf", "##conf1")
+
+	done := make(chan error, 1)
+	var ts *Lodgers
+	go func() {
+		v, err := g.Register("lodgers", &Lodgers{})
+		if err == nil {
+			ts = v.(*Lodgers)
+		}
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	case <-time.After(5 * time.Second):
+		//g is still locked, leave it
+		t.Error("child created in Start should not deadlock on its parent")
+		return
+	}
+	defer g.Close()
+	defer ts.child.Close()
+	if ts.tenant.Conf != "##conf1" {
+		t.Error("lodger should be injected from parent", ts.tenant.Conf)
+		return
+	}
+	if o, ok := ts.child.Find("conf"); !ok || o.Value != "##conf1" {
+		t.Error("child should find parent objects after Start")
+	}
+}
@@ -0,0 +1,22 @@
+package inji
+
+//TestingT is the part of testing.TB used by NewTestGraph
+type TestingT interface {
+	Cleanup(func())
+	Errorf(format string, args ...interface{})
+}
+
+//NewTestGraph create a graph closed when the test t finishes,
+//close errors fail the test, e.g.
+//	g := inji.NewTestGraph(t)
+//	g.Override("client", &FakeClient{})
+func NewTestGraph(t TestingT) *Graph {
+	g := NewGraph()
+	t.Cleanup(func() {
+		err := g.Close()
+		if err != nil {
+			t.Errorf("close test graph fail,err=%v", err)
+		}
+	})
+	return g
+}
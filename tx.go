@@ -0,0 +1,96 @@
+package inji
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+//regTx records the objects created by one top level registration,
+//so they can be rolled back if the registration fails
+type regTx struct {
+	objects []*Object
+	//objects to start when the registration ends, see StartWorkers
+	pending []*Object
+	//objects are started at once, not deferred
+	starting bool
+}
+
+//transact run fn as one registration, when fn fails(or panics) every object
+//created by it is closed on reverse order and removed from graph
+func (g *Graph) transact(fn func() (interface{}, error)) (interface{}, error) {
+	if g.tx != nil {
+		return fn()
+	}
+	tx := &regTx{}
+	g.tx = tx
+	returned := false
+	defer func() {
+		if !returned {
+			g.tx = nil
+			g.rollback(tx, errPanic)
+		}
+	}()
+	v, err := fn()
+	if err == nil && len(tx.pending) > 0 {
+		tx.starting = true
+		err = g.startAll(tx.pending)
+	}
+	returned = true
+	g.tx = nil
+	if err != nil {
+		return nil, g.rollback(tx, err)
+	}
+	return v, nil
+}
+
+//errPanic is the cause of the rollback of a panicking registration
+var errPanic = errors.New("registration panic")
+
+//track o as created by the current registration
+func (g *Graph) track(o *Object) {
+	if g.tx != nil {
+		g.tx.objects = append(g.tx.objects, o)
+	}
+}
+
+func (g *Graph) rollback(tx *regTx, cause error) error {
+	if len(tx.objects) == 0 {
+		return cause
+	}
+	var names []string
+	for i := len(tx.objects) - 1; i >= 0; i-- {
+		o := tx.objects[i]
+		if !o.template {
+			err := g.closeObject(context.Background(), o)
+			if err != nil && g.Logger != nil {
+				g.Logger.Error("rollback close fail,name=%v,err=%v", o.Name, err)
+			}
+		}
+		g.remove(o)
+		names = append(names, o.Name)
+	}
+	if g.Logger != nil {
+		g.Logger.Info("registration rolled back,objects=%v,err=%v", names, cause)
+	}
+	return fmt.Errorf("%w,rolled back=%v", cause, names)
+}
+
+//remove the keys of o set by set or setboth,
+//and o from its groups and bindings
+func (g *Graph) remove(o *Object) {
+	unwatch(o)
+	g.leaveGroups(o)
+	for _, iface := range o.implements {
+		g.unbind(iface, binding{name: o.Name})
+	}
+	if found, ok := g.findLocal(o.Name); ok && found == o {
+		g.del(o.Name)
+	}
+	if isStructPtr(o.reflectType) {
+		tn := getTypeName(o.reflectType)
+		if found, ok := g.findLocal(tn); ok && found == o {
+			g.del(tn)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package inji
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+var txEvents []string
+
+type TxA struct{}
+
+func (a *TxA) Start() error {
+	txEvents = append(txEvents, "start a")
+	return nil
+}
+
+func (a *TxA) Close() {
+	txEvents = append(txEvents, "close a")
+}
+
+type TxB struct {
+	A *TxA `inject:"a"`
+}
+
+func (b *TxB) Start() error {
+	txEvents = append(txEvents, "start b")
+	return nil
+}
+
+func (b *TxB) Close() {
+	txEvents = append(txEvents, "close b")
+}
+
+type TxFail struct{}
+
+func (f *TxFail) Start() error {
+	return errors.New("boom")
+}
+
+func (f *TxFail) Close() {
+	txEvents = append(txEvents, "close fail")
+}
+
+type TxRoot struct {
+	B    *TxB    `inject:"b"`
+	P    *TxA    `inject:"p" scope:"prototype"`
+	Fail *TxFail `inject:"fail"`
+}
+
+func TestRollback(t *testing.T) {
+	fmt.Println("############## test rollback")
+	txEvents = nil
+	g := NewGraph()
+	defer g.Close()
+
+	g.RegisterOrFail("conf", "##conf1")
+	_, err := g.Register("root", (*TxRoot)(nil))
+	if err == nil {
+		t.Error("root should fail")
+		return
+	}
+	fmt.Println(err)
+	if !strings.Contains(err.Error(), "boom") || !strings.Contains(err.Error(), "rolled back=[p b a]") {
+		t.Error("error should list rolled back objects", err)
+		return
+	}
+
+	expected := "start a,start b,start a,close a,close b,close a"
+	if strings.Join(txEvents, ",") != expected {
+		t.Error("invalid rollback order", txEvents)
+		return
+	}
+	if g.Len() != 1 {
+		t.Error("only conf should be left in graph", g.SPrint())
+		return
+	}
+
+	//rolled back objects can be registered again
+	_, err = g.Register("b", (*TxB)(nil))
+	if err != nil {
+		t.Error("b should be registered again", err)
+	}
+}
+
+type TxPanicRoot struct {
+	B *TxB        `inject:"b"`
+	P *PanicStart `inject:"panic"`
+}
+
+func TestRollbackPanic(t *testing.T) {
+	fmt.Println("############## test rollback panic")
+	txEvents = nil
+	g := NewTestGraph(t)
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("start should panic")
+			}
+		}()
+		g.Register("root", (*TxPanicRoot)(nil))
+	}()
+	if g.tx != nil || len(g.resolving) != 0 {
+		t.Error("registration should be ended by a panic", g.tx, g.resolving)
+		return
+	}
+	if g.Len() != 0 || strings.Join(txEvents, ",") != "start a,start b,close b,close a" {
+		t.Error("objects of a panicking registration should be rolled back", g.SPrint(), txEvents)
+		return
+	}
+
+	_, err := g.Register("root", (*TxRoot)(nil))
+	var cycle *CycleError
+	if err == nil || errors.As(err, &cycle) || g.Len() != 0 {
+		t.Error("later registrations should be rolled back too", err, g.SPrint())
+	}
+}
@@ -0,0 +1,133 @@
+package inji
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+//UnregisterMode tell Unregister what to do with the objects around,
+//modes can be combined like UnregisterCascade|UnregisterOrphans
+type UnregisterMode int
+
+const (
+	//UnregisterRefuse fail when other objects depend on the object
+	UnregisterRefuse UnregisterMode = 0
+	//UnregisterCascade unregister the objects depending on the object too
+	UnregisterCascade UnregisterMode = 1
+	//UnregisterOrphans unregister the dependencies auto created for
+	//the unregistered objects which nothing else depends on
+	UnregisterOrphans UnregisterMode = 2
+)
+
+//Unregister close the object of name and remove it from graph,
+//objects are closed on reverse order of registration,
+//the ones failed to close stay in graph like Close,
+//only objects of g are taken as depending on the object,
+//the ones of its children are neither refused nor cascaded,
+//and keep the closed object
+func (g *Graph) Unregister(name string, mode UnregisterMode) error {
+	g.l.Lock()
+	defer g.l.Unlock()
+
+	o, ok := g.findLocal(name)
+	if !ok {
+		return fmt.Errorf("unregister object not found,name=%s", name)
+	}
+
+	objects := g.nodes().objects
+	owners := make(map[*Object]*Object)
+	for _, obj := range objects {
+		for _, p := range obj.instances {
+			owners[p] = obj
+		}
+	}
+	//owner return the object of graph holding obj
+	owner := func(obj *Object) *Object {
+		for owners[obj] != nil {
+			obj = owners[obj]
+		}
+		return obj
+	}
+	consumers := func(obj *Object) []*Object {
+		var ret []*Object
+		for _, c := range objects {
+			for _, e := range c.deps {
+				if e.To == obj {
+					ret = append(ret, owner(c))
+					break
+				}
+			}
+		}
+		return ret
+	}
+
+	removing := map[*Object]bool{o: true}
+	queue := []*Object{o}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, c := range consumers(cur) {
+			if removing[c] {
+				continue
+			}
+			if mode&UnregisterCascade == 0 {
+				return fmt.Errorf("object is injected into %s,name=%s", c.Name, name)
+			}
+			removing[c] = true
+			queue = append(queue, c)
+		}
+	}
+
+	if mode&UnregisterOrphans != 0 {
+		for changed := true; changed; {
+			changed = false
+			for _, obj := range objects {
+				if !removing[obj] || owners[obj] != nil {
+					continue
+				}
+				for _, e := range obj.deps {
+					dep := e.To
+					if !e.Created || removing[dep] || dep.prototype || !g.isLocal(dep) {
+						continue
+					}
+					orphan := true
+					for _, c := range consumers(dep) {
+						if !removing[c] {
+							orphan = false
+							break
+						}
+					}
+					if orphan {
+						removing[dep] = true
+						changed = true
+					}
+				}
+			}
+		}
+	}
+
+	var errs []error
+	for i := len(objects) - 1; i >= 0; i-- {
+		obj := objects[i]
+		if !removing[obj] {
+			continue
+		}
+		if !obj.template {
+			err := g.closeObject(context.Background(), obj)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("close object fail,name=%s,err=%w", obj.Name, err))
+				continue
+			}
+		}
+		g.remove(obj)
+		g.emit(Event{Kind: EventUnregister, Object: obj})
+	}
+	return errors.Join(errs...)
+}
+
+//isLocal tell if o is an object of g, not of its parent
+func (g *Graph) isLocal(o *Object) bool {
+	found, ok := g.findLocal(o.Name)
+	return ok && found == o
+}
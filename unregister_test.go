@@ -0,0 +1,143 @@
+package inji
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+var unregisterEvents []string
+
+type closeRecorder struct {
+	name string
+}
+
+func (c *closeRecorder) Close() {
+	unregisterEvents = append(unregisterEvents, c.name)
+}
+
+type UnregLeaf struct {
+	closeRecorder
+}
+
+type UnregShared struct {
+	closeRecorder
+}
+
+type UnregMid struct {
+	closeRecorder
+	Leaf   *UnregLeaf   `inject:"leaf"`
+	Shared *UnregShared `inject:"shared"`
+}
+
+type UnregTop struct {
+	closeRecorder
+	Mid *UnregMid `inject:"mid"`
+}
+
+type UnregOther struct {
+	Shared *UnregShared `inject:"shared"`
+}
+
+func newUnregGraph(t *testing.T) *Graph {
+	unregisterEvents = nil
+	g := NewTestGraph(t)
+	g.RegisterOrFail("shared", &UnregShared{closeRecorder{"shared"}})
+	g.RegisterOrFail("leaf", &UnregLeaf{closeRecorder{"leaf"}})
+	g.RegisterOrFail("mid", &UnregMid{closeRecorder: closeRecorder{"mid"}})
+	g.RegisterOrFail("top", &UnregTop{closeRecorder: closeRecorder{"top"}})
+	g.RegisterOrFail("other", (*UnregOther)(nil))
+	return g
+}
+
+func TestUnregister(t *testing.T) {
+	fmt.Println("############## test unregister")
+	g := newUnregGraph(t)
+
+	err := g.Unregister("mid", UnregisterRefuse)
+	if err == nil || !strings.Contains(err.Error(), "top") {
+		t.Error("mid is injected into top", err)
+		return
+	}
+	fmt.Println(err)
+	if len(unregisterEvents) != 0 {
+		t.Error("nothing should be closed", unregisterEvents)
+		return
+	}
+
+	err = g.Unregister("top", UnregisterRefuse)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if _, ok := g.Find("top"); ok || fmt.Sprint(unregisterEvents) != "[top]" {
+		t.Error("top should be closed and removed", unregisterEvents)
+		return
+	}
+
+	err = g.Unregister("none", UnregisterRefuse)
+	if err == nil {
+		t.Error("none is not registered")
+	}
+}
+
+func TestUnregisterCascade(t *testing.T) {
+	fmt.Println("############## test unregister cascade")
+	g := newUnregGraph(t)
+	n := g.Len()
+
+	err := g.Unregister("leaf", UnregisterCascade)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if fmt.Sprint(unregisterEvents) != "[top mid leaf]" {
+		t.Error("dependents should be closed first", unregisterEvents)
+		return
+	}
+	if g.Len() != n-3 {
+		t.Error("leaf, mid and top should be removed", g.SPrint())
+		return
+	}
+	if _, ok := g.Find("shared"); !ok {
+		t.Error("shared is still used")
+	}
+}
+
+func TestUnregisterOrphans(t *testing.T) {
+	fmt.Println("############## test unregister orphans")
+	unregisterEvents = nil
+	g := NewTestGraph(t)
+
+	type autoTop struct {
+		Mid    *UnregMid    `inject:""`
+		Shared *UnregShared `inject:"shared"`
+	}
+	g.RegisterOrFail("top", (*autoTop)(nil))
+	g.RegisterOrFail("other", (*UnregOther)(nil))
+	if _, ok := g.Find("leaf"); !ok {
+		t.Error("leaf should be auto created")
+		return
+	}
+
+	err := g.Unregister("top", UnregisterOrphans)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if _, ok := g.FindByType(typeOf[*UnregMid]()); ok {
+		t.Error("auto created mid should be collected")
+		return
+	}
+	if _, ok := g.Find("leaf"); ok {
+		t.Error("auto created leaf should be collected")
+		return
+	}
+	if _, ok := g.Find("shared"); !ok {
+		t.Error("shared is still used by other")
+		return
+	}
+	if g.Len() != 2 {
+		t.Error("only shared and other should be left", g.SPrint())
+	}
+}
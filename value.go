@@ -0,0 +1,230 @@
+package inji
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+//Value is a reloadable value, a field of type *Value[T] tagged
+//`inject:"name"` follows the object registered on name,
+//Get always return the latest value set by Graph.Update
+type Value[T any] struct {
+	p atomic.Pointer[T]
+}
+
+//Get return the current value
+func (v *Value[T]) Get() T {
+	p := v.p.Load()
+	if p == nil {
+		var zero T
+		return zero
+	}
+	return *p
+}
+
+func (v *Value[T]) String() string {
+	return fmt.Sprint(v.Get())
+}
+
+func (v *Value[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.Get())
+}
+
+func (v *Value[T]) valueType() reflect.Type {
+	return typeOf[T]()
+}
+
+func (v *Value[T]) store(x interface{}) {
+	t, _ := x.(T)
+	v.p.Store(&t)
+}
+
+//dynamic is implemented by *Value[T]
+type dynamic interface {
+	valueType() reflect.Type
+	store(x interface{})
+}
+
+var dynamicType = reflect.TypeOf((*dynamic)(nil)).Elem()
+
+//watcher is a *Value[T] field of owner following an object
+type watcher struct {
+	owner *Object
+	d     dynamic
+}
+
+//watchL guard the watchers of all objects,
+//an object can be watched by objects of child graphs
+var watchL sync.Mutex
+
+//injectDynamic set the *Value[T] field f of o
+//with the object found by tag or by type T
+func (g *Graph) injectDynamic(o *Object, tag string, f reflect.StructField, vf reflect.Value, canNil bool) error {
+	holder := reflect.New(f.Type.Elem())
+	d := holder.Interface().(dynamic)
+	var found *Object
+	var ok bool
+	if tag != "" {
+		found, ok = g.find(tag)
+	} else {
+		found, ok = g.findByType(d.valueType())
+	}
+	if !ok || found == nil {
+		if canNil {
+			return nil
+		}
+		return g.missing(o, f, tag, nil)
+	}
+	if found.template {
+		return fmt.Errorf("%w,template can not be a dynamic value,field=%s,tag=%s,object %s:%v", ErrInvalidRegistration, f.Name, tag, o.Name, o.reflectType)
+	}
+	v, err := g.convert(reflect.ValueOf(found.Value), d.valueType())
+	if err != nil {
+		return g.mismatch(o, f, found, err)
+	}
+	d.store(v.Interface())
+	vf.Set(holder)
+
+	watchL.Lock()
+	found.watchers = append(found.watchers, watcher{owner: o, d: d})
+	watchL.Unlock()
+	o.deps = append(o.deps, &Edge{
+		Field:  f.Name,
+		Tag:    tag,
+		ByName: tag != "" && found.Name == tag,
+		To:     found,
+	})
+	return nil
+}
+
+//unwatch remove the watchers owned by o
+func unwatch(o *Object) {
+	watchL.Lock()
+	defer watchL.Unlock()
+	for _, e := range o.deps {
+		var kept []watcher
+		for _, w := range e.To.watchers {
+			if w.owner != o {
+				kept = append(kept, w)
+			}
+		}
+		e.To.watchers = kept
+	}
+}
+
+//rewatch add back the watchers of the *Value[T] fields of o dropped by unwatch
+func rewatch(o *Object) {
+	v := reflect.ValueOf(o.Value)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return
+	}
+	watchL.Lock()
+	defer watchL.Unlock()
+	for _, e := range o.deps {
+		vf := v.Elem().FieldByName(e.Field)
+		if !vf.IsValid() || vf.Kind() != reflect.Ptr || !vf.Type().Implements(dynamicType) || vf.IsNil() {
+			continue
+		}
+		e.To.watchers = append(e.To.watchers, watcher{owner: o, d: vf.Interface().(dynamic)})
+	}
+}
+
+//Update set the value registered on name to value,
+//value must be convertible to the type of the registered one,
+//*Value[T] fields following it get the new value,
+//then objects depending on it(directly or not) are notified
+//on the order of registration if they are Reloadable
+func (g *Graph) Update(name string, value interface{}) error {
+	o, dependents, err := g.update(name, value)
+	if err != nil {
+		return err
+	}
+	//notified without lock, so graph can be used by OnChange
+	for _, d := range dependents {
+		d.Value.(Reloadable).OnChange(o[0], o[1])
+	}
+	return nil
+}
+
+//update change the object of name, return the old and the new one,
+//and the Reloadable objects to notify
+func (g *Graph) update(name string, value interface{}) ([2]*Object, []*Object, error) {
+	g.l.Lock()
+	defer g.l.Unlock()
+
+	var ret [2]*Object
+	o, ok := g.findLocal(name)
+	if !ok {
+		return ret, nil, fmt.Errorf("update object not found,name=%s", name)
+	}
+	if o.template || isStructPtr(o.reflectType) {
+		return ret, nil, fmt.Errorf("only values can be updated,name=%s,type=%v", name, o.reflectType)
+	}
+	if value == nil || (canNil(value) && isNil(value)) {
+		return ret, nil, fmt.Errorf("update nil on name=%s", name)
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Kind() == reflect.String {
+		expanded, err := g.expand(rv.String())
+		if err != nil {
+			return ret, nil, fmt.Errorf("expand placeholders fail,name=%s,err=%w", name, err)
+		}
+		rv = reflect.ValueOf(expanded).Convert(rv.Type())
+	}
+	nv, err := g.convert(rv, o.reflectType)
+	if err != nil {
+		return ret, nil, fmt.Errorf("update type not compatible,name=%s,err=%w", name, err)
+	}
+	//decorators of o wrap the new value too
+	decorated, decorators, err := g.redecorate(o, nv.Interface())
+	if err != nil {
+		return ret, nil, fmt.Errorf("update decorate fail,name=%s,err=%w", name, err)
+	}
+
+	//convert for every watcher first, nothing is changed on failure
+	watchL.Lock()
+	defer watchL.Unlock()
+	values := make([]reflect.Value, len(o.watchers))
+	for i, w := range o.watchers {
+		values[i], err = g.convert(reflect.ValueOf(decorated), w.d.valueType())
+		if err != nil {
+			return ret, nil, fmt.Errorf("update type not compatible,name=%s,field of %s,err=%w", name, w.owner.Name, err)
+		}
+	}
+
+	ret[0] = &Object{Name: o.Name, reflectType: o.reflectType, Value: o.Value}
+	ret[1] = o
+	o.Value = nv.Interface()
+	o.decorated, o.decorators = decorated, decorators
+	for i, w := range o.watchers {
+		w.d.store(values[i].Interface())
+	}
+
+	changed := map[*Object]bool{o: true}
+	var dependents []*Object
+	objects := g.nodes().objects
+	for found := true; found; {
+		found = false
+		for _, obj := range objects {
+			if changed[obj] {
+				continue
+			}
+			for _, e := range obj.deps {
+				if changed[e.To] {
+					changed[obj] = true
+					found = true
+					break
+				}
+			}
+		}
+	}
+	for _, obj := range objects {
+		if _, ok := obj.Value.(Reloadable); ok && obj != o && changed[obj] {
+			dependents = append(dependents, obj)
+		}
+	}
+	return ret, dependents, nil
+}
@@ -0,0 +1,122 @@
+package inji
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+type ReloadUser struct {
+	Timeout *Value[time.Duration] `inject:"timeout"`
+	Limit   *Value[int64]         `inject:"limit"`
+	Fixed   int                   `inject:"limit"`
+	changes []string
+}
+
+func (u *ReloadUser) OnChange(old, new *Object) {
+	u.changes = append(u.changes, fmt.Sprintf("user:%s:%v->%v", new.Name, old.Value, new.Value))
+}
+
+type ReloadHost struct {
+	User    *ReloadUser `inject:""`
+	changes *[]string
+}
+
+func (h *ReloadHost) OnChange(old, new *Object) {
+	*h.changes = append(*h.changes, "host:"+new.Name)
+}
+
+func TestUpdate(t *testing.T) {
+	fmt.Println("############## test update")
+	g := NewGraph()
+	defer g.Close()
+
+	g.RegisterOrFail("timeout", time.Second)
+	g.RegisterOrFail("limit", 10)
+	u := g.RegisterOrFail("user", (*ReloadUser)(nil)).(*ReloadUser)
+	h := g.RegisterOrFail("host", (*ReloadHost)(nil)).(*ReloadHost)
+	h.changes = &u.changes
+	if u.Timeout.Get() != time.Second || u.Limit.Get() != 10 || u.Fixed != 10 {
+		t.Error("invalid initial values", u.Timeout, u.Limit, u.Fixed)
+		return
+	}
+	b, err := json.Marshal(u)
+	if err != nil || !strings.Contains(string(b), `"Limit":10`) {
+		t.Error("value should be marshaled as json", string(b), err)
+		return
+	}
+
+	err = g.Update("timeout", "3s")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if u.Timeout.Get() != 3*time.Second {
+		t.Error("dynamic value should be updated", u.Timeout)
+		return
+	}
+	if found, _ := g.Find("timeout"); found.Value != 3*time.Second {
+		t.Error("object should be updated", found.Value)
+		return
+	}
+	err = g.Update("limit", 20)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if u.Limit.Get() != 20 || u.Fixed != 10 {
+		t.Error("only dynamic value should be updated", u.Limit, u.Fixed)
+		return
+	}
+	want := []string{"user:timeout:1s->3s", "host:timeout", "user:limit:10->20", "host:limit"}
+	if fmt.Sprint(u.changes) != fmt.Sprint(want) {
+		t.Error("dependents should be notified on dependency order", u.changes)
+		return
+	}
+}
+
+func TestUpdateInvalid(t *testing.T) {
+	fmt.Println("############## test update invalid")
+	g := NewGraph()
+	defer g.Close()
+
+	type smallUser struct {
+		Small *Value[int8] `inject:"limit"`
+	}
+	g.RegisterOrFail("limit", 10)
+	small := g.RegisterOrFail("small", (*smallUser)(nil)).(*smallUser)
+
+	err := g.Update("none", 1)
+	if err == nil {
+		t.Error("none is not registered")
+		return
+	}
+	fmt.Println(err)
+
+	err = g.Update("limit", "abc")
+	if err == nil {
+		t.Error("abc is not an int")
+		return
+	}
+	fmt.Println(err)
+
+	err = g.Update("small", &smallUser{})
+	if err == nil {
+		t.Error("struct ptr can not be updated")
+		return
+	}
+	fmt.Println(err)
+
+	err = g.Update("limit", 1000)
+	if err == nil {
+		t.Error("1000 overflows int8")
+		return
+	}
+	fmt.Println(err)
+	if found, _ := g.Find("limit"); found.Value != 10 || small.Small.Get() != 10 {
+		t.Error("failed update should change nothing", found.Value)
+		return
+	}
+}